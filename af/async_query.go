@@ -0,0 +1,218 @@
+package af
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"unsafe"
+
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/parser"
+	"github.com/taosdata/driver-go/v3/errors"
+	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/cgo"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
+)
+
+// Block is a raw block of the result passed to the callback of QueryAsync.
+// The block memory belongs to the result and is only valid until the callback returns.
+type Block struct {
+	header    *wrapper.RowsHeader
+	precision int
+	rows      int
+	block     unsafe.Pointer
+}
+
+// Columns Return the column names
+func (b *Block) Columns() []string {
+	return b.header.ColNames
+}
+
+// ColumnTypes Return the column types
+func (b *Block) ColumnTypes() []uint8 {
+	return b.header.ColTypes
+}
+
+// Header Return the columns header of the result
+func (b *Block) Header() *wrapper.RowsHeader {
+	return b.header
+}
+
+// Precision Return the timestamp precision of the result
+func (b *Block) Precision() int {
+	return b.precision
+}
+
+// NumRows Return the number of the rows in the block
+func (b *Block) NumRows() int {
+	return b.rows
+}
+
+// Raw Return the raw block pointer, it must not be used after the callback returns
+func (b *Block) Raw() unsafe.Pointer {
+	return b.block
+}
+
+// Values Copy the rows of the block into driver values
+func (b *Block) Values() ([][]driver.Value, error) {
+	return parser.ReadBlock(b.block, b.rows, b.header.ColTypes, b.precision)
+}
+
+// asyncQuery drives a query by the taosc callbacks, no goroutine waits for the query.
+// Each callback continues in a short-lived goroutine, so the user callback and the cgo calls
+// do not run on the taosc callback thread. A cancelable context is watched until the query is released,
+// once the context is done the result of the query is stopped, or released as soon as it arrives.
+// The connection is never killed since it is shared by the other queries.
+type asyncQuery struct {
+	ctx       context.Context
+	lock      sync.Mutex
+	released  bool
+	done      chan struct{}
+	fn        func(block *Block, err error) bool
+	handle    cgo.Handle
+	result    unsafe.Pointer
+	header    *wrapper.RowsHeader
+	precision int
+	locker    *thread.Locker
+}
+
+func (q *asyncQuery) QueryCall(res unsafe.Pointer, code int) {
+	go q.onQuery(res, code)
+}
+
+func (q *asyncQuery) FetchCall(res unsafe.Pointer, numOfRows int) {
+	go q.onFetch(res, numOfRows)
+}
+
+func (q *asyncQuery) onQuery(res unsafe.Pointer, code int) {
+	q.lock.Lock()
+	q.result = res
+	q.lock.Unlock()
+	if err := q.ctx.Err(); err != nil {
+		q.finish(err)
+		return
+	}
+	if code != int(errors.SUCCESS) {
+		q.finish(q.queryError(errors.NewError(code, wrapper.TaosErrorStr(res))))
+		return
+	}
+	numFields := wrapper.TaosNumFields(res)
+	header, err := wrapper.ReadColumn(res, numFields)
+	if err != nil {
+		q.finish(err)
+		return
+	}
+	q.header = header
+	q.precision = wrapper.TaosResultPrecision(res)
+	q.fetch()
+}
+
+func (q *asyncQuery) onFetch(res unsafe.Pointer, numOfRows int) {
+	if numOfRows < 0 {
+		q.finish(q.queryError(errors.NewError(wrapper.TaosError(res), wrapper.TaosErrorStr(res))))
+		return
+	}
+	if numOfRows == 0 {
+		// a stopped query may end without an error
+		q.finish(q.queryError(nil))
+		return
+	}
+	block := &Block{
+		header:    q.header,
+		precision: q.precision,
+		rows:      numOfRows,
+		block:     wrapper.TaosGetRawBlock(res),
+	}
+	if !q.fn(block, nil) {
+		q.release()
+		return
+	}
+	q.fetch()
+}
+
+func (q *asyncQuery) fetch() {
+	if err := q.ctx.Err(); err != nil {
+		q.finish(err)
+		return
+	}
+	q.locker.Lock()
+	wrapper.TaosFetchRawBlockA(q.result, q.handle)
+	q.locker.Unlock()
+}
+
+// queryError returns the context error instead of the result of the stopped query
+func (q *asyncQuery) queryError(err error) error {
+	if ctxErr := q.ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// watch stops the result when the context is done before the query is released,
+// a query without result yet is released by onQuery
+func (q *asyncQuery) watch() {
+	select {
+	case <-q.done:
+	case <-q.ctx.Done():
+		q.lock.Lock()
+		if !q.released && q.result != nil {
+			q.locker.Lock()
+			wrapper.TaosStopQuery(q.result)
+			q.locker.Unlock()
+		}
+		q.lock.Unlock()
+	}
+}
+
+// finish reports the end of the stream and releases the query
+func (q *asyncQuery) finish(err error) {
+	q.release()
+	q.fn(nil, err)
+}
+
+func (q *asyncQuery) release() {
+	q.lock.Lock()
+	q.released = true
+	if q.result != nil {
+		q.locker.Lock()
+		wrapper.TaosFreeResult(q.result)
+		q.locker.Unlock()
+		q.result = nil
+	}
+	q.lock.Unlock()
+	close(q.done)
+	q.handle.Delete()
+}
+
+// QueryAsync Execute query sql without waiting for the result, the blocks of the result are passed to fn
+// as soon as they are fetched. fn is called with a nil block and a nil error after the last block,
+// or with a nil block and the error once the query fails, it is not called anymore after that.
+// Returning false from fn stops fetching the remaining blocks and fn is not called again.
+// The context is checked before each fetch and the result of the query is stopped once the context is done,
+// a canceled context ends the stream with its error. Canceling one query does not affect the other queries
+// of the connector.
+// The request id is taken from the context by common.ReqIDKey.
+func (conn *Connector) QueryAsync(ctx context.Context, sql string, fn func(block *Block, err error) bool) error {
+	if conn.taos == nil {
+		return driver.ErrBadConn
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	reqID, err := common.GetReqIDFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if reqID == 0 {
+		reqID = common.GetReqID()
+	}
+	q := &asyncQuery{ctx: ctx, done: make(chan struct{}), fn: fn, locker: conn.locker}
+	q.handle = cgo.NewHandle(q)
+	conn.locker.Lock()
+	wrapper.TaosQueryAWithReqID(conn.taos, sql, q.handle, reqID)
+	conn.locker.Unlock()
+	if ctx.Done() != nil {
+		go q.watch()
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package af
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type asyncQueryResult struct {
+	values [][]driver.Value
+	err    error
+}
+
+func TestQueryAsync(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		err := db.Close()
+		assert.NoError(t, err)
+	}()
+	_, err := db.Exec("create table if not exists test_query_async(ts timestamp, v int)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = db.Exec("insert into test_query_async values(now, 1)(now+1s, 2)(now+2s, 3)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	done := make(chan *asyncQueryResult, 1)
+	result := &asyncQueryResult{}
+	err = db.QueryAsync(context.Background(), "select ts, v from test_query_async", func(block *Block, err error) bool {
+		if block == nil {
+			result.err = err
+			done <- result
+			return false
+		}
+		assert.Equal(t, []string{"ts", "v"}, block.Columns())
+		values, err := block.Values()
+		if !assert.NoError(t, err) {
+			return false
+		}
+		result.values = append(result.values, values...)
+		return true
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	select {
+	case r := <-done:
+		assert.NoError(t, r.err)
+		if !assert.Equal(t, 3, len(r.values)) {
+			return
+		}
+		assert.Equal(t, int32(1), r.values[0][1])
+		assert.Equal(t, int32(3), r.values[2][1])
+	case <-time.After(time.Second * 10):
+		t.Fatal("query async timeout")
+	}
+}
+
+func TestQueryAsyncError(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		err := db.Close()
+		assert.NoError(t, err)
+	}()
+	done := make(chan error, 1)
+	err := db.QueryAsync(context.Background(), "select * from table_not_exist", func(block *Block, err error) bool {
+		assert.Nil(t, block)
+		done <- err
+		return false
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	select {
+	case err = <-done:
+		assert.Error(t, err)
+	case <-time.After(time.Second * 10):
+		t.Fatal("query async timeout")
+	}
+}
+
+func TestQueryAsyncStop(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		err := db.Close()
+		assert.NoError(t, err)
+	}()
+	called := make(chan struct{}, 10)
+	err := db.QueryAsync(context.Background(), "select * from information_schema.ins_columns", func(block *Block, err error) bool {
+		called <- struct{}{}
+		return false
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	select {
+	case <-called:
+	case <-time.After(time.Second * 10):
+		t.Fatal("query async timeout")
+	}
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, 0, len(called))
+}
+
+func TestQueryAsyncCancel(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		err := db.Close()
+		assert.NoError(t, err)
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	err := db.QueryAsync(ctx, "select * from information_schema.ins_columns, information_schema.ins_tables", func(block *Block, err error) bool {
+		if block == nil {
+			done <- err
+		}
+		return true
+	})
+	if !assert.NoError(t, err) {
+		cancel()
+		return
+	}
+	cancel()
+	select {
+	case err = <-done:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second * 10):
+		t.Fatal("query async cancel timeout")
+	}
+	// the connection is usable after the query is stopped
+	_, err = db.Exec("select 1")
+	assert.NoError(t, err)
+}
+
+func TestQueryAsyncCancelOne(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		err := db.Close()
+		assert.NoError(t, err)
+	}()
+	_, err := db.Exec("create table if not exists test_query_async_cancel(ts timestamp, v int)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = db.Exec("insert into test_query_async_cancel values(now, 1)(now+1s, 2)(now+2s, 3)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error, 1)
+	err = db.QueryAsync(ctx, "select * from information_schema.ins_columns, information_schema.ins_tables", func(block *Block, err error) bool {
+		if block == nil {
+			canceled <- err
+		}
+		return true
+	})
+	if !assert.NoError(t, err) {
+		cancel()
+		return
+	}
+	done := make(chan *asyncQueryResult, 1)
+	result := &asyncQueryResult{}
+	err = db.QueryAsync(context.Background(), "select ts, v from test_query_async_cancel", func(block *Block, err error) bool {
+		if block == nil {
+			result.err = err
+			done <- result
+			return false
+		}
+		values, err := block.Values()
+		if !assert.NoError(t, err) {
+			return false
+		}
+		result.values = append(result.values, values...)
+		return true
+	})
+	cancel()
+	if !assert.NoError(t, err) {
+		return
+	}
+	select {
+	case err = <-canceled:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second * 10):
+		t.Fatal("query async cancel timeout")
+	}
+	select {
+	case r := <-done:
+		assert.NoError(t, r.err)
+		assert.Equal(t, 3, len(r.values))
+	case <-time.After(time.Second * 10):
+		t.Fatal("query async timeout")
+	}
+}
@@ -2,61 +2,106 @@ package af
 
 import "C"
 import (
+	"context"
 	"database/sql/driver"
+	"net"
 	"unsafe"
 
 	"github.com/taosdata/driver-go/v3/af/async"
 	"github.com/taosdata/driver-go/v3/af/insertstmt"
 	"github.com/taosdata/driver-go/v3/af/locker"
+	"github.com/taosdata/driver-go/v3/af/tmq"
 	"github.com/taosdata/driver-go/v3/common"
 	"github.com/taosdata/driver-go/v3/common/param"
 	"github.com/taosdata/driver-go/v3/errors"
 	taosError "github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/cgo"
 	"github.com/taosdata/driver-go/v3/wrapper/handler"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
 type Connector struct {
-	taos unsafe.Pointer
+	taos     unsafe.Pointer
+	notifier *handler.Notifier
+	locker   *thread.Locker
 }
 
-// NewConnector New connector with TDengine connection
+// NewConnector New connector with TDengine connection, the cgo calls are limited by the default locker
 func NewConnector(taos unsafe.Pointer) (*Connector, error) {
+	return NewConnectorWithLocker(taos, locker.Default())
+}
+
+// NewConnectorWithLocker New connector with TDengine connection, the cgo calls are limited by l
+func NewConnectorWithLocker(taos unsafe.Pointer, l *thread.Locker) (*Connector, error) {
 	if taos == nil {
 		return nil, errors.ErrTscInvalidConnection
 	}
-	return &Connector{taos: taos}, nil
+	return &Connector{taos: taos, locker: l}, nil
 }
 
-// Open New connector with TDengine connection information
+// Open New connector with TDengine connection information, the cgo calls are limited by the default locker
 func Open(host, user, pass, db string, port int) (*Connector, error) {
+	return OpenWithLocker(host, user, pass, db, port, locker.Default())
+}
+
+// OpenWithLocker New connector with TDengine connection information, the cgo calls are limited by l,
+// so the connectors sharing l do not starve the others
+func OpenWithLocker(host, user, pass, db string, port int, l *thread.Locker) (*Connector, error) {
 	if len(user) == 0 {
 		user = common.DefaultUser
 	}
 	if len(pass) == 0 {
 		pass = common.DefaultPassword
 	}
-	locker.Lock()
+	l.Lock()
 	tc, err := wrapper.TaosConnect(host, user, pass, db, port)
-	locker.Unlock()
+	l.Unlock()
 	if err != nil {
 		return nil, err
 	}
-	return &Connector{taos: tc}, nil
+	return &Connector{taos: tc, locker: l}, nil
+}
+
+// Locker Return the locker limiting the cgo calls of the connector
+func (conn *Connector) Locker() *thread.Locker {
+	return conn.locker
 }
 
 // Close Release TDengine connection
 func (conn *Connector) Close() error {
-	locker.Lock()
+	conn.locker.Lock()
 	wrapper.TaosClose(conn.taos)
-	locker.Unlock()
+	conn.locker.Unlock()
 	conn.taos = nil
+	if conn.notifier != nil {
+		conn.notifier.Close()
+		conn.notifier = nil
+	}
+	return nil
+}
+
+// SetNotifyHandler Set the handler of the notifications sent by the server, such as password change,
+// whitelist change and user dropped. The handler is called in order in a separate goroutine,
+// set nil to drop the notifications.
+func (conn *Connector) SetNotifyHandler(fn func(common.NotifyEvent)) error {
+	if conn.taos == nil {
+		return driver.ErrBadConn
+	}
+	if conn.notifier == nil {
+		notifier, err := handler.RegisterNotifier(conn.taos, conn.locker)
+		if err != nil {
+			return err
+		}
+		conn.notifier = notifier
+	}
+	conn.notifier.SetHandler(fn)
 	return nil
 }
 
 // StmtExecute Execute sql through stmt
 func (conn *Connector) StmtExecute(sql string, params *param.Param) (res driver.Result, err error) {
-	stmt := NewStmt(conn.taos)
+	stmt := newStmt(conn.taos, 0, conn.locker)
 	if stmt == nil {
 		return nil, &errors.TaosError{Code: 0xffff, ErrStr: "failed to init stmt"}
 	}
@@ -69,7 +114,7 @@ func (conn *Connector) StmtExecute(sql string, params *param.Param) (res driver.
 
 // StmtExecuteWithReqID Execute sql through stmt with reqID
 func (conn *Connector) StmtExecuteWithReqID(sql string, params *param.Param, reqID int64) (res driver.Result, err error) {
-	stmt := NewStmtWithReqID(conn.taos, reqID)
+	stmt := newStmt(conn.taos, reqID, conn.locker)
 	if stmt == nil {
 		err = &errors.TaosError{Code: 0xffff, ErrStr: "failed to init stmt"}
 		return
@@ -104,24 +149,24 @@ func (conn *Connector) stmtExecute(stmt *Stmt, sql string, params *param.Param)
 
 // Exec Execute sql
 func (conn *Connector) Exec(query string, args ...driver.Value) (driver.Result, error) {
-	if conn.taos == nil {
-		return nil, driver.ErrBadConn
-	}
-	if len(args) != 0 {
-		prepared, err := common.InterpolateParams(query, common.ValueArgsToNamedValueArgs(args))
-		if err != nil {
-			return nil, err
-		}
-		query = prepared
-	}
-	asyncHandler := async.GetHandler()
-	defer async.PutHandler(asyncHandler)
-	result := conn.taosQuery(query, asyncHandler, 0)
-	return conn.processExecResult(result)
+	return conn.exec(context.Background(), query, 0, args)
 }
 
 // ExecWithReqID Execute sql with reqID
 func (conn *Connector) ExecWithReqID(query string, reqID int64, args ...driver.Value) (driver.Result, error) {
+	return conn.exec(context.Background(), query, reqID, args)
+}
+
+// ExecContext Execute sql with context, the query is killed when the context is done
+func (conn *Connector) ExecContext(ctx context.Context, query string, args ...driver.Value) (driver.Result, error) {
+	reqID, err := common.GetReqIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.exec(ctx, query, reqID, args)
+}
+
+func (conn *Connector) exec(ctx context.Context, query string, reqID int64, args []driver.Value) (driver.Result, error) {
 	if conn.taos == nil {
 		return nil, driver.ErrBadConn
 	}
@@ -133,17 +178,20 @@ func (conn *Connector) ExecWithReqID(query string, reqID int64, args ...driver.V
 		query = prepared
 	}
 	asyncHandler := async.GetHandler()
-	defer async.PutHandler(asyncHandler)
-	result := conn.taosQuery(query, asyncHandler, reqID)
+	result, err := conn.taosQuery(ctx, query, asyncHandler, reqID)
+	if err != nil {
+		return nil, err
+	}
+	async.PutHandler(asyncHandler)
 	return conn.processExecResult(result)
 }
 
 func (conn *Connector) processExecResult(result *handler.AsyncResult) (driver.Result, error) {
 	defer func() {
 		if result != nil && result.Res != nil {
-			locker.Lock()
+			conn.locker.Lock()
 			wrapper.TaosFreeResult(result.Res)
-			locker.Unlock()
+			conn.locker.Unlock()
 		}
 	}()
 	res := result.Res
@@ -157,23 +205,24 @@ func (conn *Connector) processExecResult(result *handler.AsyncResult) (driver.Re
 
 // Query Execute query sql
 func (conn *Connector) Query(query string, args ...driver.Value) (driver.Rows, error) {
-	if conn.taos == nil {
-		return nil, driver.ErrBadConn
-	}
-	if len(args) != 0 {
-		prepared, err := common.InterpolateParams(query, common.ValueArgsToNamedValueArgs(args))
-		if err != nil {
-			return nil, err
-		}
-		query = prepared
-	}
-	h := async.GetHandler()
-	result := conn.taosQuery(query, h, 0)
-	return conn.processQueryResult(result, h)
+	return conn.query(context.Background(), query, 0, args)
 }
 
 // QueryWithReqID Execute query sql with reqID
 func (conn *Connector) QueryWithReqID(query string, reqID int64, args ...driver.Value) (driver.Rows, error) {
+	return conn.query(context.Background(), query, reqID, args)
+}
+
+// QueryContext Execute query sql with context, the query is killed and the rows are released when the context is done
+func (conn *Connector) QueryContext(ctx context.Context, query string, args ...driver.Value) (driver.Rows, error) {
+	reqID, err := common.GetReqIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.query(ctx, query, reqID, args)
+}
+
+func (conn *Connector) query(ctx context.Context, query string, reqID int64, args []driver.Value) (driver.Rows, error) {
 	if conn.taos == nil {
 		return nil, driver.ErrBadConn
 	}
@@ -185,18 +234,21 @@ func (conn *Connector) QueryWithReqID(query string, reqID int64, args ...driver.
 		query = prepared
 	}
 	h := async.GetHandler()
-	result := conn.taosQuery(query, h, reqID)
-	return conn.processQueryResult(result, h)
+	result, err := conn.taosQuery(ctx, query, h, reqID)
+	if err != nil {
+		return nil, err
+	}
+	return conn.processQueryResult(ctx, result, h)
 }
 
-func (conn *Connector) processQueryResult(result *handler.AsyncResult, h *handler.Handler) (driver.Rows, error) {
+func (conn *Connector) processQueryResult(ctx context.Context, result *handler.AsyncResult, h *handler.Handler) (driver.Rows, error) {
 	res := result.Res
 	if code := wrapper.TaosError(res); code != int(errors.SUCCESS) {
 		async.PutHandler(h)
 		errStr := wrapper.TaosErrorStr(res)
-		locker.Lock()
+		conn.locker.Lock()
 		wrapper.TaosFreeResult(result.Res)
-		locker.Unlock()
+		conn.locker.Unlock()
 		return nil, errors.NewError(code, errStr)
 	}
 	numFields := wrapper.TaosNumFields(res)
@@ -207,51 +259,74 @@ func (conn *Connector) processQueryResult(result *handler.AsyncResult, h *handle
 	}
 	precision := wrapper.TaosResultPrecision(res)
 	rs := &rows{
+		ctx:        ctx,
 		handler:    h,
 		rowsHeader: rowsHeader,
 		result:     res,
 		precision:  precision,
+		locker:     conn.locker,
 	}
 	return rs, nil
 }
 
-func (conn *Connector) taosQuery(sqlStr string, handler *handler.Handler, reqID int64) *handler.AsyncResult {
-	locker.Lock()
+// taosQuery runs the query asynchronously and waits for the result or the context to be done.
+// When the context is done the result is stopped and released once the pending callback arrives,
+// the connection is not killed so the other queries sharing it go on. The caller must not use the handler anymore.
+func (conn *Connector) taosQuery(ctx context.Context, sqlStr string, handler *handler.Handler, reqID int64) (*handler.AsyncResult, error) {
+	if err := ctx.Err(); err != nil {
+		async.PutHandler(handler)
+		return nil, err
+	}
+	conn.locker.Lock()
 	if reqID == 0 {
 		wrapper.TaosQueryA(conn.taos, sqlStr, handler.Handler)
 	} else {
 		wrapper.TaosQueryAWithReqID(conn.taos, sqlStr, handler.Handler, reqID)
 	}
-	locker.Unlock()
-	r := <-handler.Caller.QueryResult
-	return r
+	conn.locker.Unlock()
+	select {
+	case r := <-handler.Caller.QueryResult:
+		return r, nil
+	case <-ctx.Done():
+		go func() {
+			r := <-handler.Caller.QueryResult
+			if r.Res != nil {
+				conn.locker.Lock()
+				wrapper.TaosStopQuery(r.Res)
+				wrapper.TaosFreeResult(r.Res)
+				conn.locker.Unlock()
+			}
+			async.PutHandler(handler)
+		}()
+		return nil, ctx.Err()
+	}
 }
 
 // InsertStmt Prepare batch insert stmt
 func (conn *Connector) InsertStmt() *insertstmt.InsertStmt {
-	return insertstmt.NewInsertStmt(conn.taos)
+	return insertstmt.NewInsertStmtWithLocker(conn.taos, 0, conn.locker)
 }
 
 // Stmt Prepare stmt
 func (conn *Connector) Stmt() *Stmt {
-	return NewStmt(conn.taos)
+	return newStmt(conn.taos, 0, conn.locker)
 }
 
 // Stmt2 Prepare stmt2
 func (conn *Connector) Stmt2(reqID int64, singleTableBindOnce bool) *Stmt2 {
-	return NewStmt2(conn.taos, reqID, singleTableBindOnce)
+	return newStmt2(conn.taos, reqID, singleTableBindOnce, conn.locker)
 }
 
 // InsertStmtWithReqID Prepare batch insert stmt with reqID
 func (conn *Connector) InsertStmtWithReqID(reqID int64) *insertstmt.InsertStmt {
-	return insertstmt.NewInsertStmtWithReqID(conn.taos, reqID)
+	return insertstmt.NewInsertStmtWithLocker(conn.taos, reqID, conn.locker)
 }
 
 // SelectDB Execute `use db`
 func (conn *Connector) SelectDB(db string) error {
-	locker.Lock()
+	conn.locker.Lock()
 	code := wrapper.TaosSelectDB(conn.taos, db)
-	locker.Unlock()
+	conn.locker.Unlock()
 	if code != 0 {
 		return taosError.NewError(code, wrapper.TaosErrorStr(nil))
 	}
@@ -261,35 +336,35 @@ func (conn *Connector) SelectDB(db string) error {
 // InfluxDBInsertLines Insert data using influxdb line format
 // Deprecated
 func (conn *Connector) InfluxDBInsertLines(lines []string, precision string) error {
-	locker.Lock()
+	conn.locker.Lock()
 	result := wrapper.TaosSchemalessInsert(conn.taos, lines, wrapper.InfluxDBLineProtocol, precision)
-	locker.Unlock()
+	conn.locker.Unlock()
 	code := wrapper.TaosError(result)
 	if code != 0 {
 		errStr := wrapper.TaosErrorStr(result)
-		locker.Lock()
+		conn.locker.Lock()
 		wrapper.TaosFreeResult(result)
-		locker.Unlock()
+		conn.locker.Unlock()
 		return errors.NewError(code, errStr)
 	}
-	locker.Lock()
+	conn.locker.Lock()
 	wrapper.TaosFreeResult(result)
-	locker.Unlock()
+	conn.locker.Unlock()
 	return nil
 }
 
 // OpenTSDBInsertTelnetLines Insert data using opentsdb telnet format
 // Deprecated
 func (conn *Connector) OpenTSDBInsertTelnetLines(lines []string) error {
-	locker.Lock()
+	conn.locker.Lock()
 	result := wrapper.TaosSchemalessInsert(conn.taos, lines, wrapper.OpenTSDBTelnetLineProtocol, "")
-	locker.Unlock()
+	conn.locker.Unlock()
 	code := wrapper.TaosError(result)
 	if code != 0 {
 		errStr := wrapper.TaosErrorStr(result)
-		locker.Lock()
+		conn.locker.Lock()
 		wrapper.TaosFreeResult(result)
-		locker.Unlock()
+		conn.locker.Unlock()
 		return errors.NewError(code, errStr)
 	}
 	wrapper.TaosFreeResult(result)
@@ -303,14 +378,14 @@ func (conn *Connector) OpenTSDBInsertJsonPayload(payload string) error {
 	code := wrapper.TaosError(result)
 	if code != 0 {
 		errStr := wrapper.TaosErrorStr(result)
-		locker.Lock()
+		conn.locker.Lock()
 		wrapper.TaosFreeResult(result)
-		locker.Unlock()
+		conn.locker.Unlock()
 		return errors.NewError(code, errStr)
 	}
-	locker.Lock()
+	conn.locker.Lock()
 	wrapper.TaosFreeResult(result)
-	locker.Unlock()
+	conn.locker.Unlock()
 	return nil
 }
 
@@ -323,15 +398,84 @@ func (conn *Connector) GetTableVGroupID(db, table string) (vgID int, err error)
 	return
 }
 
+// GetTablesVGroupIDs Get the vgroup ids of the tables in one request, the ids are in the order of the tables
+func (conn *Connector) GetTablesVGroupIDs(db string, tables []string) ([]int, error) {
+	if conn.taos == nil {
+		return nil, driver.ErrBadConn
+	}
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	conn.locker.Lock()
+	vgIDs, code := wrapper.TaosGetTablesVgID(conn.taos, db, tables)
+	conn.locker.Unlock()
+	if code != 0 {
+		return nil, errors.NewError(code, wrapper.TaosErrorStr(nil))
+	}
+	return vgIDs, nil
+}
+
+// GetWhitelist Get the ip whitelist of the connected user, the pending request is abandoned when the context is done
+func (conn *Connector) GetWhitelist(ctx context.Context) ([]net.IPNet, error) {
+	if conn.taos == nil {
+		return nil, driver.ErrBadConn
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c := make(chan *wrapper.WhitelistResult, 1)
+	h := cgo.NewHandle(c)
+	conn.locker.Lock()
+	wrapper.TaosFetchWhitelistA(conn.taos, h)
+	conn.locker.Unlock()
+	select {
+	case result := <-c:
+		h.Delete()
+		if result.ErrCode != 0 {
+			return nil, errors.NewError(int(result.ErrCode), wrapper.TMQErr2Str(result.ErrCode))
+		}
+		ipNets := make([]net.IPNet, len(result.IPNets))
+		for i, ipNet := range result.IPNets {
+			ipNets[i] = *ipNet
+		}
+		return ipNets, nil
+	case <-ctx.Done():
+		// the handle is released after the pending callback arrives
+		go func() {
+			<-c
+			h.Delete()
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// WriteRaw Write the raw message polled by tmq.Consumer.PollRaw, the data and the schema changes are written as they are.
+// The message is not released by WriteRaw.
+func (conn *Connector) WriteRaw(message *tmq.RawMessage) error {
+	if conn.taos == nil {
+		return driver.ErrBadConn
+	}
+	if message == nil || message.Raw() == nil {
+		return &errors.TaosError{Code: 0xffff, ErrStr: "invalid raw message"}
+	}
+	conn.locker.Lock()
+	errCode := wrapper.TMQWriteRaw(conn.taos, message.Raw())
+	conn.locker.Unlock()
+	if errCode != 0 {
+		return errors.NewError(int(errCode), wrapper.TMQErr2Str(errCode))
+	}
+	return nil
+}
+
 // InfluxDBInsertLinesWithReqID Insert data using influxdb line format
 func (conn *Connector) InfluxDBInsertLinesWithReqID(lines string, precision string, reqID int64, ttl int, tbNameKey string) error {
-	locker.Lock()
+	conn.locker.Lock()
 	_, result := wrapper.TaosSchemalessInsertRawTTLWithReqIDTBNameKey(conn.taos, lines, wrapper.InfluxDBLineProtocol, precision, ttl, reqID, tbNameKey)
-	locker.Unlock()
+	conn.locker.Unlock()
 	defer func() {
-		locker.Lock()
+		conn.locker.Lock()
 		wrapper.TaosFreeResult(result)
-		locker.Unlock()
+		conn.locker.Unlock()
 	}()
 	code := wrapper.TaosError(result)
 	if code != 0 {
@@ -343,13 +487,13 @@ func (conn *Connector) InfluxDBInsertLinesWithReqID(lines string, precision stri
 
 // OpenTSDBInsertTelnetLinesWithReqID Insert data using opentsdb telnet format
 func (conn *Connector) OpenTSDBInsertTelnetLinesWithReqID(lines string, reqID int64, ttl int, tbNameKey string) error {
-	locker.Lock()
+	conn.locker.Lock()
 	_, result := wrapper.TaosSchemalessInsertRawTTLWithReqIDTBNameKey(conn.taos, lines, wrapper.OpenTSDBTelnetLineProtocol, "", ttl, reqID, tbNameKey)
-	locker.Unlock()
+	conn.locker.Unlock()
 	defer func() {
-		locker.Lock()
+		conn.locker.Lock()
 		wrapper.TaosFreeResult(result)
-		locker.Unlock()
+		conn.locker.Unlock()
 	}()
 	code := wrapper.TaosError(result)
 	if code != 0 {
@@ -361,13 +505,13 @@ func (conn *Connector) OpenTSDBInsertTelnetLinesWithReqID(lines string, reqID in
 
 // OpenTSDBInsertJsonPayloadWithReqID Insert data using opentsdb json format
 func (conn *Connector) OpenTSDBInsertJsonPayloadWithReqID(payload string, reqID int64, ttl int, tbNameKey string) error {
-	locker.Lock()
+	conn.locker.Lock()
 	_, result := wrapper.TaosSchemalessInsertRawTTLWithReqIDTBNameKey(conn.taos, payload, wrapper.OpenTSDBJsonFormatProtocol, "", ttl, reqID, tbNameKey)
-	locker.Unlock()
+	conn.locker.Unlock()
 	defer func() {
-		locker.Lock()
+		conn.locker.Lock()
 		wrapper.TaosFreeResult(result)
-		locker.Unlock()
+		conn.locker.Unlock()
 	}()
 	code := wrapper.TaosError(result)
 	if code != 0 {
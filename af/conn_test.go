@@ -1,6 +1,7 @@
 package af
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"io"
@@ -10,9 +11,12 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	afTmq "github.com/taosdata/driver-go/v3/af/tmq"
 	"github.com/taosdata/driver-go/v3/common"
 	param2 "github.com/taosdata/driver-go/v3/common/param"
+	"github.com/taosdata/driver-go/v3/common/tmq"
 	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
 func TestMain(m *testing.M) {
@@ -78,6 +82,26 @@ func TestOpen(t *testing.T) {
 	}
 }
 
+func TestOpenWithLocker(t *testing.T) {
+	l := thread.NewLocker(1)
+	db, err := OpenWithLocker("", "", "", "", 0, l)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	assert.Equal(t, l, db.Locker())
+	acquired := l.Stats().Acquired
+	_, err = db.Exec("select server_version()")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Greater(t, l.Stats().Acquired, acquired)
+	assert.Equal(t, 0, l.Stats().Holders)
+}
+
 // @author: xftan
 // @date: 2022/1/27 16:07
 // @description: test query
@@ -950,6 +974,53 @@ func TestConnector_QueryWithReqID(t *testing.T) {
 	}
 }
 
+func TestConnector_QueryContext(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		err := db.Close()
+		assert.NoError(t, err)
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := db.QueryContext(ctx, "select 1")
+	assert.Equal(t, context.Canceled, err)
+	_, err = db.ExecContext(ctx, "create stable if not exists meters (ts timestamp, current float) tags (groupId int)")
+	assert.Equal(t, context.Canceled, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	res, err := db.QueryContext(ctx, "select 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := make([]driver.Value, 1)
+	err = res.Next(v)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v[0])
+	err = res.Close()
+	assert.NoError(t, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	res, err = db.QueryContext(ctx, "select * from information_schema.ins_columns")
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	cancel()
+	v = make([]driver.Value, len(res.Columns()))
+	for err == nil {
+		err = res.Next(v)
+	}
+	assert.Equal(t, context.Canceled, err)
+	// the later calls return the same error
+	err = res.Next(v)
+	assert.Equal(t, context.Canceled, err)
+	err = res.Close()
+	assert.NoError(t, err)
+	_, err = db.ExecContext(context.Background(), "select 1")
+	assert.NoError(t, err)
+}
+
 func TestInfluxDBInsertLinesWithReqID(t *testing.T) {
 	db := testDatabase(t)
 	defer func() {
@@ -1088,3 +1159,156 @@ func TestDecimal(t *testing.T) {
 	err = rows.Close()
 	assert.NoError(t, err)
 }
+
+func TestSetNotifyHandler(t *testing.T) {
+	db, err := Open("", "", "", "", 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_, _ = db.Exec("drop user t_af_notify")
+		_ = db.Close()
+	}()
+	_, _ = db.Exec("drop user t_af_notify")
+	_, err = db.Exec("create user t_af_notify pass 'notify_123'")
+	if !assert.NoError(t, err) {
+		return
+	}
+	conn, err := Open("", "t_af_notify", "notify_123", "", 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	events := make(chan common.NotifyEvent, 3)
+	err = conn.SetNotifyHandler(func(event common.NotifyEvent) {
+		events <- event
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	waitEvent := func(want common.NotifyType) {
+		select {
+		case event := <-events:
+			assert.Equal(t, want, event.Type)
+		case <-time.After(time.Second * 5):
+			t.Errorf("wait for %s timeout", want)
+		}
+	}
+	_, err = db.Exec("alter user t_af_notify pass 'test_123'")
+	assert.NoError(t, err)
+	waitEvent(common.NotifyPasswordChanged)
+	_, err = db.Exec("alter user t_af_notify add host '192.168.1.98/0','192.168.1.98/32'")
+	assert.NoError(t, err)
+	waitEvent(common.NotifyWhitelistChanged)
+	_, err = db.Exec("drop user t_af_notify")
+	assert.NoError(t, err)
+	waitEvent(common.NotifyUserDropped)
+}
+
+func TestGetWhitelist(t *testing.T) {
+	db, err := Open("", "", "", "", 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	ipNets, err := db.GetWhitelist(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Equal(t, 1, len(ipNets)) {
+		assert.Equal(t, "0.0.0.0/0", ipNets[0].String())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = db.GetWhitelist(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestWriteRaw(t *testing.T) {
+	db, err := Open("", "", "", "", 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_, _ = db.Exec("drop topic if exists test_af_write_raw")
+		_, _ = db.Exec("drop database if exists test_af_write_raw_src")
+		_, _ = db.Exec("drop database if exists test_af_write_raw_dst")
+		_ = db.Close()
+	}()
+	for _, sql := range []string{
+		"drop topic if exists test_af_write_raw",
+		"drop database if exists test_af_write_raw_src",
+		"drop database if exists test_af_write_raw_dst",
+		"create database test_af_write_raw_src vgroups 1 wal_retention_period 3600",
+		"create database test_af_write_raw_dst vgroups 1",
+		"create stable test_af_write_raw_src.st (ts timestamp, v int) tags (t int)",
+		"create topic test_af_write_raw with meta as database test_af_write_raw_src",
+		"insert into test_af_write_raw_src.ct0 using test_af_write_raw_src.st tags(1) values(now, 1)",
+	} {
+		_, err = db.Exec(sql)
+		if !assert.NoError(t, err, sql) {
+			return
+		}
+	}
+	target, err := Open("", "", "", "test_af_write_raw_dst", 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = target.Close()
+	}()
+	consumer, err := afTmq.NewConsumer(&tmq.ConfigMap{
+		"group.id":           "test",
+		"auto.offset.reset":  "earliest",
+		"td.connect.ip":      "127.0.0.1",
+		"td.connect.user":    "root",
+		"td.connect.pass":    "taosdata",
+		"td.connect.port":    "6030",
+		"enable.auto.commit": "false",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = consumer.Close()
+	}()
+	err = consumer.Subscribe("test_af_write_raw", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i := 0; i < 5; i++ {
+		event := consumer.PollRaw(500)
+		if event == nil {
+			continue
+		}
+		message, ok := event.(*afTmq.RawMessage)
+		if !assert.True(t, ok, event.String()) {
+			return
+		}
+		err = target.WriteRaw(message)
+		message.Free()
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = consumer.CommitOffsets([]tmq.TopicPartition{message.TopicPartition})
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+	rows, err := target.Query("select v from test_af_write_raw_dst.st")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	values := make([]driver.Value, 1)
+	if assert.NoError(t, rows.Next(values)) {
+		assert.Equal(t, int32(1), values[0])
+	}
+}
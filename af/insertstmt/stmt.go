@@ -9,30 +9,39 @@ import (
 	"github.com/taosdata/driver-go/v3/common/param"
 	taosError "github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
 type InsertStmt struct {
-	stmt unsafe.Pointer
+	stmt   unsafe.Pointer
+	locker *thread.Locker
 }
 
 func NewInsertStmt(taosConn unsafe.Pointer) *InsertStmt {
-	locker.Lock()
-	stmt := wrapper.TaosStmtInit(taosConn)
-	locker.Unlock()
-	return &InsertStmt{stmt: stmt}
+	return NewInsertStmtWithLocker(taosConn, 0, locker.Default())
 }
 
 func NewInsertStmtWithReqID(taosConn unsafe.Pointer, reqID int64) *InsertStmt {
-	locker.Lock()
-	stmt := wrapper.TaosStmtInitWithReqID(taosConn, reqID)
-	locker.Unlock()
-	return &InsertStmt{stmt: stmt}
+	return NewInsertStmtWithLocker(taosConn, reqID, locker.Default())
+}
+
+// NewInsertStmtWithLocker New insert stmt whose cgo calls are limited by l, reqID 0 means no request id
+func NewInsertStmtWithLocker(taosConn unsafe.Pointer, reqID int64, l *thread.Locker) *InsertStmt {
+	var stmt unsafe.Pointer
+	l.Lock()
+	if reqID == 0 {
+		stmt = wrapper.TaosStmtInit(taosConn)
+	} else {
+		stmt = wrapper.TaosStmtInitWithReqID(taosConn, reqID)
+	}
+	l.Unlock()
+	return &InsertStmt{stmt: stmt, locker: l}
 }
 
 func (stmt *InsertStmt) Prepare(sql string) error {
-	locker.Lock()
+	stmt.locker.Lock()
 	code := wrapper.TaosStmtPrepare(stmt.stmt, sql)
-	locker.Unlock()
+	stmt.locker.Unlock()
 	if code != 0 {
 		return stmt.stmtErr(code)
 	}
@@ -47,9 +56,9 @@ func (stmt *InsertStmt) Prepare(sql string) error {
 }
 
 func (stmt *InsertStmt) SetTableName(name string) error {
-	locker.Lock()
+	stmt.locker.Lock()
 	code := wrapper.TaosStmtSetTBName(stmt.stmt, name)
-	locker.Unlock()
+	stmt.locker.Unlock()
 	if code != 0 {
 		return stmt.stmtErr(code)
 	}
@@ -57,9 +66,9 @@ func (stmt *InsertStmt) SetTableName(name string) error {
 }
 
 func (stmt *InsertStmt) SetSubTableName(name string) error {
-	locker.Lock()
+	stmt.locker.Lock()
 	code := wrapper.TaosStmtSetSubTBName(stmt.stmt, name)
-	locker.Unlock()
+	stmt.locker.Unlock()
 	if code != 0 {
 		return stmt.stmtErr(code)
 	}
@@ -67,9 +76,9 @@ func (stmt *InsertStmt) SetSubTableName(name string) error {
 }
 
 func (stmt *InsertStmt) SetTableNameWithTags(tableName string, tags *param.Param) error {
-	locker.Lock()
+	stmt.locker.Lock()
 	code := wrapper.TaosStmtSetTBNameTags(stmt.stmt, tableName, tags.GetValues())
-	locker.Unlock()
+	stmt.locker.Unlock()
 	if code != 0 {
 		return stmt.stmtErr(code)
 	}
@@ -86,9 +95,9 @@ func (stmt *InsertStmt) BindParam(params []*param.Param, bindType *param.ColumnT
 	if err != nil {
 		return err
 	}
-	locker.Lock()
+	stmt.locker.Lock()
 	code := wrapper.TaosStmtBindParamBatch(stmt.stmt, data, columnTypes)
-	locker.Unlock()
+	stmt.locker.Unlock()
 	if code != 0 {
 		return stmt.stmtErr(code)
 	}
@@ -96,9 +105,9 @@ func (stmt *InsertStmt) BindParam(params []*param.Param, bindType *param.ColumnT
 }
 
 func (stmt *InsertStmt) AddBatch() error {
-	locker.Lock()
+	stmt.locker.Lock()
 	code := wrapper.TaosStmtAddBatch(stmt.stmt)
-	locker.Unlock()
+	stmt.locker.Unlock()
 	if code != 0 {
 		return stmt.stmtErr(code)
 	}
@@ -106,9 +115,9 @@ func (stmt *InsertStmt) AddBatch() error {
 }
 
 func (stmt *InsertStmt) Execute() error {
-	locker.Lock()
+	stmt.locker.Lock()
 	code := wrapper.TaosStmtExecute(stmt.stmt)
-	locker.Unlock()
+	stmt.locker.Unlock()
 	if code != 0 {
 		return stmt.stmtErr(code)
 	}
@@ -120,9 +129,9 @@ func (stmt *InsertStmt) GetAffectedRows() int {
 }
 
 func (stmt *InsertStmt) Close() error {
-	locker.Lock()
+	stmt.locker.Lock()
 	code := wrapper.TaosStmtClose(stmt.stmt)
-	locker.Unlock()
+	stmt.locker.Unlock()
 	var err error
 	if code != 0 {
 		err = stmt.stmtErr(code)
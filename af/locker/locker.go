@@ -10,21 +10,22 @@ import (
 var locker *thread.Locker
 var once = sync.Once{}
 
+// Default Return the locker shared by the connectors which are not opened with their own locker
+func Default() *thread.Locker {
+	once.Do(func() {
+		locker = thread.NewLocker(runtime.NumCPU())
+	})
+	return locker
+}
+
 func Lock() {
-	if locker == nil {
-		SetMaxThreadSize(runtime.NumCPU())
-	}
-	locker.Lock()
+	Default().Lock()
 }
 func Unlock() {
-	if locker == nil {
-		SetMaxThreadSize(runtime.NumCPU())
-	}
-	locker.Unlock()
+	Default().Unlock()
 }
 
+// SetMaxThreadSize Resize the default locker
 func SetMaxThreadSize(size int) {
-	once.Do(func() {
-		locker = thread.NewLocker(size)
-	})
+	Default().SetSize(size)
 }
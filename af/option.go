@@ -0,0 +1,73 @@
+package af
+
+import (
+	"database/sql/driver"
+	"strconv"
+
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/errors"
+	"github.com/taosdata/driver-go/v3/wrapper"
+)
+
+// ConnOption is the option of a connection set by SetOption.
+type ConnOption int
+
+const (
+	// ConnOptionCharset sets the charset of the connection.
+	ConnOptionCharset ConnOption = iota
+	// ConnOptionTimezone sets the timezone of the connection, such as Asia/Shanghai.
+	ConnOptionTimezone
+	// ConnOptionUserIP sets the ip of the user shown by the server, such as in `show connections`.
+	ConnOptionUserIP
+	// ConnOptionUserApp sets the app name of the user shown by the server.
+	ConnOptionUserApp
+	// ConnOptionBIMode enables the BI mode when the value is true, which is used by the BI tools
+	// to list the tables without the child tables.
+	ConnOptionBIMode
+)
+
+var connOptions = map[ConnOption]int{
+	ConnOptionCharset:  common.TSDB_OPTION_CONNECTION_CHARSET,
+	ConnOptionTimezone: common.TSDB_OPTION_CONNECTION_TIMEZONE,
+	ConnOptionUserIP:   common.TSDB_OPTION_CONNECTION_USER_IP,
+	ConnOptionUserApp:  common.TSDB_OPTION_CONNECTION_USER_APP,
+}
+
+// SetOption Set the option of the connection without affecting other connections,
+// an empty value resets the option to the default except ConnOptionBIMode
+func (conn *Connector) SetOption(option ConnOption, value string) error {
+	if conn.taos == nil {
+		return driver.ErrBadConn
+	}
+	var code int
+	if option == ConnOptionBIMode {
+		enable, err := strconv.ParseBool(value)
+		if err != nil {
+			return &errors.TaosError{Code: 0xffff, ErrStr: "invalid bool value: " + value}
+		}
+		mode := 0
+		if enable {
+			mode = 1
+		}
+		conn.locker.Lock()
+		code = wrapper.TaosSetConnMode(conn.taos, common.TAOS_CONN_MODE_BI, mode)
+	} else {
+		cOption, ok := connOptions[option]
+		if !ok {
+			return &errors.TaosError{Code: 0xffff, ErrStr: "invalid connection option: " + strconv.Itoa(int(option))}
+		}
+		var v *string
+		if value != "" {
+			v = &value
+		}
+		conn.locker.Lock()
+		code = wrapper.TaosOptionsConnection(conn.taos, cOption, v)
+	}
+	if code != 0 {
+		errStr := wrapper.TaosErrorStr(nil)
+		conn.locker.Unlock()
+		return errors.NewError(code, errStr)
+	}
+	conn.locker.Unlock()
+	return nil
+}
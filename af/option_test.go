@@ -0,0 +1,25 @@
+package af
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOption(t *testing.T) {
+	db, err := Open("", "", "", "", 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	assert.NoError(t, db.SetOption(ConnOptionTimezone, "Asia/Shanghai"))
+	assert.NoError(t, db.SetOption(ConnOptionCharset, "UTF-8"))
+	assert.NoError(t, db.SetOption(ConnOptionUserIP, "192.168.1.1"))
+	assert.NoError(t, db.SetOption(ConnOptionUserApp, "test_app"))
+	assert.NoError(t, db.SetOption(ConnOptionTimezone, ""))
+	assert.NoError(t, db.SetOption(ConnOptionBIMode, "true"))
+	assert.Error(t, db.SetOption(ConnOptionBIMode, "wrong"))
+	assert.Error(t, db.SetOption(ConnOption(100), "value"))
+}
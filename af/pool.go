@@ -0,0 +1,299 @@
+package af
+
+import (
+	"context"
+	"database/sql/driver"
+	stdErrors "errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/taosdata/driver-go/v3/af/locker"
+	"github.com/taosdata/driver-go/v3/errors"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
+)
+
+const (
+	defaultHealthCheckInterval = time.Second * 30
+	minPoolMaintainInterval    = time.Second
+)
+
+// ErrPoolClosed is returned by Pool.Get when the pool is closed.
+var ErrPoolClosed = &errors.TaosError{Code: 0xffff, ErrStr: "pool is closed"}
+
+// PoolConfig is the configuration of Pool.
+type PoolConfig struct {
+	Host     string
+	User     string
+	Password string
+	DB       string
+	Port     int
+	// MinSize is the number of the connections kept open.
+	MinSize int
+	// MaxSize is the max number of the open connections, the default is the number of CPUs,
+	// which is also the default limit of the concurrent cgo calls.
+	MaxSize int
+	// IdleTimeout closes the connections idle for longer than it except the MinSize ones, 0 means never.
+	IdleTimeout time.Duration
+	// HealthCheckInterval is the idle time after which a connection is probed before it is returned by Get,
+	// the default is 30 seconds and a negative value disables the probing.
+	HealthCheckInterval time.Duration
+	// CgoThread limits the concurrent cgo calls of the connections of the pool by their own locker when it is positive,
+	// otherwise the connections share the default locker of af.
+	CgoThread int
+}
+
+// Pool is a pool of Connector. A connection is used by one caller between Get and Put,
+// so the statements and the session state such as `use db` stay on the same connection.
+type Pool struct {
+	config  PoolConfig
+	locker  *thread.Locker
+	tokens  chan struct{}
+	lock    sync.Mutex
+	idle    []*idleConn
+	numOpen int
+	closed  bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+type idleConn struct {
+	conn      *Connector
+	idleSince time.Time
+}
+
+// NewPool New pool and open MinSize connections
+func NewPool(config PoolConfig) (*Pool, error) {
+	if config.MaxSize <= 0 {
+		config.MaxSize = runtime.NumCPU()
+	}
+	if config.MinSize > config.MaxSize {
+		config.MinSize = config.MaxSize
+	}
+	if config.HealthCheckInterval == 0 {
+		config.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	p := &Pool{
+		config: config,
+		locker: locker.Default(),
+		tokens: make(chan struct{}, config.MaxSize),
+		done:   make(chan struct{}),
+	}
+	if config.CgoThread > 0 {
+		p.locker = thread.NewLocker(config.CgoThread)
+	}
+	for i := 0; i < config.MinSize; i++ {
+		conn, err := p.open()
+		if err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+		p.idle = append(p.idle, &idleConn{conn: conn, idleSince: time.Now()})
+	}
+	p.wg.Add(1)
+	go p.maintain()
+	return p, nil
+}
+
+func (p *Pool) open() (*Connector, error) {
+	conn, err := OpenWithLocker(p.config.Host, p.config.User, p.config.Password, p.config.DB, p.config.Port, p.locker)
+	if err != nil {
+		return nil, err
+	}
+	p.lock.Lock()
+	p.numOpen += 1
+	p.lock.Unlock()
+	return conn, nil
+}
+
+func (p *Pool) closeConn(conn *Connector) {
+	_ = conn.Close()
+	p.lock.Lock()
+	p.numOpen -= 1
+	p.lock.Unlock()
+}
+
+// Get Get a connection from the pool, it waits for a connection to be put back when MaxSize connections are in use.
+// The idle connections are probed before returned as configured by HealthCheckInterval.
+func (p *Pool) Get(ctx context.Context) (*Connector, error) {
+	select {
+	case p.tokens <- struct{}{}:
+	case <-p.done:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	conn, err := p.get(ctx)
+	if err != nil {
+		<-p.tokens
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (p *Pool) get(ctx context.Context) (*Connector, error) {
+	for {
+		p.lock.Lock()
+		if p.closed {
+			p.lock.Unlock()
+			return nil, ErrPoolClosed
+		}
+		if len(p.idle) == 0 {
+			p.lock.Unlock()
+			return p.open()
+		}
+		ic := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.lock.Unlock()
+		if p.config.HealthCheckInterval < 0 || time.Since(ic.idleSince) < p.config.HealthCheckInterval {
+			return ic.conn, nil
+		}
+		if err := probe(ctx, ic.conn); err == nil {
+			return ic.conn, nil
+		} else if ctx.Err() != nil {
+			p.closeConn(ic.conn)
+			return nil, ctx.Err()
+		}
+		// reconnect by the next idle connection or a new one
+		p.closeConn(ic.conn)
+	}
+}
+
+func probe(ctx context.Context, conn *Connector) error {
+	_, err := conn.ExecContext(ctx, "select server_version()")
+	return err
+}
+
+// Put Put the connection back to the pool, a closed connection is discarded
+func (p *Pool) Put(conn *Connector) {
+	if conn.taos == nil {
+		p.Discard(conn)
+		return
+	}
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		p.closeConn(conn)
+		<-p.tokens
+		return
+	}
+	p.idle = append(p.idle, &idleConn{conn: conn, idleSince: time.Now()})
+	p.lock.Unlock()
+	<-p.tokens
+}
+
+// Discard Close the connection got from the pool instead of putting it back, such as when it is broken
+func (p *Pool) Discard(conn *Connector) {
+	p.closeConn(conn)
+	<-p.tokens
+}
+
+// WithConn Call fn with a connection of the pool, the connection is discarded when fn returns
+// an error of a broken connection, so the next call reconnects
+func (p *Pool) WithConn(ctx context.Context, fn func(conn *Connector) error) error {
+	conn, err := p.Get(ctx)
+	if err != nil {
+		return err
+	}
+	err = fn(conn)
+	if isBadConn(err) {
+		p.Discard(conn)
+	} else {
+		p.Put(conn)
+	}
+	return err
+}
+
+func isBadConn(err error) bool {
+	if err == nil {
+		return false
+	}
+	if stdErrors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var taosErr *errors.TaosError
+	return stdErrors.As(err, &taosErr) && taosErr.Code == errors.TSC_INVALID_CONNECTION
+}
+
+// maintain closes the expired idle connections and keeps MinSize connections open.
+func (p *Pool) maintain() {
+	defer p.wg.Done()
+	interval := p.config.IdleTimeout / 2
+	if interval < minPoolMaintainInterval {
+		interval = minPoolMaintainInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+		var expired []*Connector
+		p.lock.Lock()
+		if p.config.IdleTimeout > 0 {
+			kept := p.idle[:0]
+			numOpen := p.numOpen
+			for _, ic := range p.idle {
+				if numOpen > p.config.MinSize && time.Since(ic.idleSince) > p.config.IdleTimeout {
+					expired = append(expired, ic.conn)
+					numOpen -= 1
+					continue
+				}
+				kept = append(kept, ic)
+			}
+			p.idle = kept
+		}
+		missing := p.config.MinSize - p.numOpen + len(expired)
+		p.lock.Unlock()
+		for _, conn := range expired {
+			p.closeConn(conn)
+		}
+		for i := 0; i < missing; i++ {
+			conn, err := p.open()
+			if err != nil {
+				break
+			}
+			p.lock.Lock()
+			if p.closed {
+				p.lock.Unlock()
+				p.closeConn(conn)
+				return
+			}
+			p.idle = append(p.idle, &idleConn{conn: conn, idleSince: time.Now()})
+			p.lock.Unlock()
+		}
+	}
+}
+
+// Locker Return the locker limiting the cgo calls of the connections of the pool, it can be resized at runtime
+func (p *Pool) Locker() *thread.Locker {
+	return p.locker
+}
+
+// Stats Return the number of the open connections and the idle ones
+func (p *Pool) Stats() (open int, idle int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.numOpen, len(p.idle)
+}
+
+// Close Close the idle connections and stop the pool, the connections in use are closed when they are put back
+func (p *Pool) Close() error {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.lock.Unlock()
+	close(p.done)
+	p.wg.Wait()
+	for _, ic := range idle {
+		p.closeConn(ic.conn)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package af
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/errors"
+)
+
+func TestIsBadConn(t *testing.T) {
+	assert.False(t, isBadConn(nil))
+	assert.True(t, isBadConn(driver.ErrBadConn))
+	assert.True(t, isBadConn(errors.ErrTscInvalidConnection))
+	assert.True(t, isBadConn(fmt.Errorf("exec: %w", errors.NewError(int(errors.TSC_INVALID_CONNECTION), "Invalid connection"))))
+	assert.False(t, isBadConn(errors.NewError(0x2600, "syntax error")))
+}
+
+func TestPool(t *testing.T) {
+	pool, err := NewPool(PoolConfig{MinSize: 1, MaxSize: 2, IdleTimeout: time.Second, HealthCheckInterval: -1})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		assert.NoError(t, pool.Close())
+	}()
+	open, idle := pool.Stats()
+	assert.Equal(t, 1, open)
+	assert.Equal(t, 1, idle)
+	conn1, err := pool.Get(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	conn2, err := pool.Get(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the pool is exhausted
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	_, err = pool.Get(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	pool.Put(conn1)
+	pool.Discard(conn2)
+	open, idle = pool.Stats()
+	assert.Equal(t, 1, open)
+	assert.Equal(t, 1, idle)
+	err = pool.WithConn(context.Background(), func(conn *Connector) error {
+		_, err := conn.Exec("select server_version()")
+		return err
+	})
+	assert.NoError(t, err)
+	err = pool.WithConn(context.Background(), func(conn *Connector) error {
+		return driver.ErrBadConn
+	})
+	assert.Equal(t, driver.ErrBadConn, err)
+	// the broken connection is discarded and the min size is restored
+	time.Sleep(time.Second * 2)
+	open, idle = pool.Stats()
+	assert.Equal(t, 1, open)
+	assert.Equal(t, 1, idle)
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	pool, err := NewPool(PoolConfig{MaxSize: 1, HealthCheckInterval: time.Millisecond})
+	if !assert.NoError(t, err) {
+		return
+	}
+	conn, err := pool.Get(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	pool.Put(conn)
+	// the broken idle connection is replaced by a new one
+	_ = conn.Close()
+	time.Sleep(time.Millisecond * 10)
+	conn, err = pool.Get(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = conn.Exec("select server_version()")
+	assert.NoError(t, err)
+	pool.Put(conn)
+	assert.NoError(t, pool.Close())
+	_, err = pool.Get(context.Background())
+	assert.Equal(t, ErrPoolClosed, err)
+}
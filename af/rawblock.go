@@ -0,0 +1,69 @@
+package af
+
+import (
+	"context"
+	"database/sql/driver"
+	"unsafe"
+
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/param"
+	"github.com/taosdata/driver-go/v3/common/serializer"
+	"github.com/taosdata/driver-go/v3/errors"
+	"github.com/taosdata/driver-go/v3/wrapper"
+)
+
+// WriteRawBlock Write the columns into the table as a raw block, the columns must match the columns of the table in order.
+// The request id is taken from the context by common.ReqIDKey.
+func (conn *Connector) WriteRawBlock(ctx context.Context, table string, cols []*param.Param, types *param.ColumnType) error {
+	return conn.writeRawBlock(ctx, table, cols, types, nil)
+}
+
+// WriteRawBlockWithFields Write the columns into the table as a raw block, the columns are matched to the columns of the table by the fields,
+// so the block can contain a part of the columns of the table in any order.
+// The request id is taken from the context by common.ReqIDKey.
+func (conn *Connector) WriteRawBlockWithFields(ctx context.Context, table string, cols []*param.Param, types *param.ColumnType, fields []*wrapper.RawBlockField) error {
+	if len(fields) != len(cols) {
+		return &errors.TaosError{Code: 0xffff, ErrStr: "number of fields does not match the columns"}
+	}
+	return conn.writeRawBlock(ctx, table, cols, types, fields)
+}
+
+func (conn *Connector) writeRawBlock(ctx context.Context, table string, cols []*param.Param, types *param.ColumnType, fields []*wrapper.RawBlockField) error {
+	if conn.taos == nil {
+		return driver.ErrBadConn
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(cols) == 0 {
+		return &errors.TaosError{Code: 0xffff, ErrStr: "no columns to write"}
+	}
+	reqID, err := common.GetReqIDFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if reqID == 0 {
+		reqID = common.GetReqID()
+	}
+	block, err := serializer.SerializeRawBlock(cols, types)
+	if err != nil {
+		return err
+	}
+	rows := len(cols[0].GetValues())
+	var code int
+	if fields == nil {
+		conn.locker.Lock()
+		code = wrapper.TaosWriteRawBlockWithReqID(conn.taos, rows, unsafe.Pointer(&block[0]), table, reqID)
+		conn.locker.Unlock()
+	} else {
+		cFields := wrapper.NewTaosFields(fields)
+		defer wrapper.FreeTaosFields(cFields)
+		conn.locker.Lock()
+		code = wrapper.TaosWriteRawBlockWithFieldsWithReqID(conn.taos, rows, unsafe.Pointer(&block[0]), table, cFields, len(fields), reqID)
+		conn.locker.Unlock()
+	}
+	if code != 0 {
+		return errors.NewError(code, wrapper.TaosErrorStr(nil))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package af
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/param"
+	"github.com/taosdata/driver-go/v3/wrapper"
+)
+
+func TestWriteRawBlock(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		_ = db.Close()
+	}()
+	_, err := db.Exec("create table if not exists test_af.t_raw_block(ts timestamp, v int, s binary(20))")
+	if !assert.NoError(t, err) {
+		return
+	}
+	now := time.Now().Round(time.Millisecond)
+	cols := []*param.Param{
+		param.NewParam(2).AddTimestamp(now, common.PrecisionMicroSecond).AddTimestamp(now.Add(time.Second), common.PrecisionMicroSecond),
+		param.NewParam(2).AddInt(1).AddNull(),
+		param.NewParam(2).AddBinary([]byte("a")).AddBinary([]byte("b")),
+	}
+	types := param.NewColumnType(3).AddTimestamp().AddInt().AddBinary(20)
+	err = db.WriteRawBlock(context.Background(), "t_raw_block", cols, types)
+	if !assert.NoError(t, err) {
+		return
+	}
+	fieldsCols := []*param.Param{
+		param.NewParam(1).AddBinary([]byte("c")),
+		param.NewParam(1).AddTimestamp(now.Add(time.Second*2), common.PrecisionMicroSecond),
+	}
+	fieldsTypes := param.NewColumnType(2).AddBinary(20).AddTimestamp()
+	fields := []*wrapper.RawBlockField{
+		{Name: "s", Type: common.TSDB_DATA_TYPE_BINARY, Bytes: 22},
+		{Name: "ts", Type: common.TSDB_DATA_TYPE_TIMESTAMP, Bytes: 8},
+	}
+	err = db.WriteRawBlockWithFields(context.Background(), "t_raw_block", fieldsCols, fieldsTypes, fields)
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = db.WriteRawBlockWithFields(context.Background(), "t_raw_block", fieldsCols, fieldsTypes, fields[:1])
+	assert.Error(t, err)
+	rows, err := db.Query("select v, s from test_af.t_raw_block order by ts")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	var got [][]interface{}
+	for {
+		values := make([]driver.Value, 2)
+		if rows.Next(values) != nil {
+			break
+		}
+		got = append(got, []interface{}{values[0], values[1]})
+	}
+	assert.Equal(t, [][]interface{}{{int32(1), "a"}, {nil, "b"}, {nil, "c"}}, got)
+}
@@ -0,0 +1,205 @@
+package af
+
+import (
+	"sync"
+
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/stmt"
+	"github.com/taosdata/driver-go/v3/errors"
+)
+
+// VGroupWriter writes the binds of the tables in one vgroup. A writer is created for each vgroup by VGroupRouter
+// and is never called concurrently, so it can hold its own connection or Stmt2.
+type VGroupWriter interface {
+	Write(binds []*stmt.TaosStmt2BindData) error
+	Close() error
+}
+
+// VGroupRouter partitions the binds by the vgroups of the tables and dispatches each partition to the writer of the vgroup,
+// so each request only writes into one vgroup. The vgroup ids of the tables are cached after the first lookup.
+type VGroupRouter struct {
+	conn        *Connector
+	db          string
+	newWriter   func(vgID int) (VGroupWriter, error)
+	cacheLock   sync.RWMutex
+	vgIDs       map[string]int
+	writersLock sync.Mutex
+	writers     map[int]*vgroupWriter
+	closed      bool
+}
+
+type vgroupWriter struct {
+	lock   sync.Mutex
+	writer VGroupWriter
+}
+
+// NewVGroupRouter New router writing into the tables of db, conn is used to look up the vgroup ids of the tables
+// and newWriter is called once for each vgroup when it is first written.
+func NewVGroupRouter(conn *Connector, db string, newWriter func(vgID int) (VGroupWriter, error)) *VGroupRouter {
+	return &VGroupRouter{
+		conn:      conn,
+		db:        db,
+		newWriter: newWriter,
+		vgIDs:     map[string]int{},
+		writers:   map[int]*vgroupWriter{},
+	}
+}
+
+// Partition Group the indexes of the tables by vgroup id, the tables not in the cache are looked up in one request
+func (r *VGroupRouter) Partition(tables []string) (map[int][]int, error) {
+	var missing []string
+	r.cacheLock.RLock()
+	for _, table := range tables {
+		if _, ok := r.vgIDs[table]; !ok {
+			missing = append(missing, table)
+		}
+	}
+	r.cacheLock.RUnlock()
+	if len(missing) != 0 {
+		vgIDs, err := r.conn.GetTablesVGroupIDs(r.db, missing)
+		if err != nil {
+			return nil, err
+		}
+		r.cacheLock.Lock()
+		for i, table := range missing {
+			r.vgIDs[table] = vgIDs[i]
+		}
+		r.cacheLock.Unlock()
+	}
+	partitions := map[int][]int{}
+	r.cacheLock.RLock()
+	for i, table := range tables {
+		vgID := r.vgIDs[table]
+		partitions[vgID] = append(partitions[vgID], i)
+	}
+	r.cacheLock.RUnlock()
+	return partitions, nil
+}
+
+// Write Write the binds by the writers of their vgroups, the vgroups are written concurrently
+// and the first error is returned after all the writes are done. The writers of all the vgroups are resolved
+// before any write starts, so a writer creation failure writes nothing. A failed vgroup write does not
+// roll back the other vgroups, which may already be written.
+func (r *VGroupRouter) Write(binds []*stmt.TaosStmt2BindData) error {
+	tables := make([]string, len(binds))
+	for i, bind := range binds {
+		tables[i] = bind.TableName
+	}
+	partitions, err := r.Partition(tables)
+	if err != nil {
+		return err
+	}
+	writers := make(map[int]*vgroupWriter, len(partitions))
+	for vgID := range partitions {
+		writer, err := r.getWriter(vgID)
+		if err != nil {
+			return err
+		}
+		writers[vgID] = writer
+	}
+	errs := make(chan error, len(partitions))
+	wg := sync.WaitGroup{}
+	for vgID, indexes := range partitions {
+		writer := writers[vgID]
+		partition := make([]*stmt.TaosStmt2BindData, len(indexes))
+		for i, index := range indexes {
+			partition[i] = binds[index]
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			writer.lock.Lock()
+			defer writer.lock.Unlock()
+			errs <- writer.writer.Write(partition)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *VGroupRouter) getWriter(vgID int) (*vgroupWriter, error) {
+	r.writersLock.Lock()
+	defer r.writersLock.Unlock()
+	if r.closed {
+		return nil, &errors.TaosError{Code: 0xffff, ErrStr: "router is closed"}
+	}
+	if writer, ok := r.writers[vgID]; ok {
+		return writer, nil
+	}
+	w, err := r.newWriter(vgID)
+	if err != nil {
+		return nil, err
+	}
+	writer := &vgroupWriter{writer: w}
+	r.writers[vgID] = writer
+	return writer, nil
+}
+
+// Close Close the writers of all the vgroups, the connection for lookups is not closed
+func (r *VGroupRouter) Close() error {
+	r.writersLock.Lock()
+	defer r.writersLock.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	var firstErr error
+	for _, writer := range r.writers {
+		writer.lock.Lock()
+		err := writer.writer.Close()
+		writer.lock.Unlock()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	r.writers = nil
+	return firstErr
+}
+
+type stmt2VGroupWriter struct {
+	conn *Connector
+	stmt *Stmt2
+}
+
+// NewStmt2VGroupWriter Return the factory of VGroupRouter writers, each writer opens its own connection by connect
+// and writes the binds by a Stmt2 prepared with the insert sql
+func NewStmt2VGroupWriter(connect func() (*Connector, error), sql string) func(vgID int) (VGroupWriter, error) {
+	return func(vgID int) (VGroupWriter, error) {
+		conn, err := connect()
+		if err != nil {
+			return nil, err
+		}
+		s := conn.Stmt2(common.GetReqID(), false)
+		if s.stmt2 == nil {
+			_ = conn.Close()
+			return nil, &errors.TaosError{Code: 0xffff, ErrStr: "failed to init stmt2"}
+		}
+		err = s.Prepare(sql)
+		if err != nil {
+			_ = s.Close()
+			_ = conn.Close()
+			return nil, err
+		}
+		return &stmt2VGroupWriter{conn: conn, stmt: s}, nil
+	}
+}
+
+func (w *stmt2VGroupWriter) Write(binds []*stmt.TaosStmt2BindData) error {
+	err := w.stmt.Bind(binds)
+	if err != nil {
+		return err
+	}
+	return w.stmt.Execute()
+}
+
+func (w *stmt2VGroupWriter) Close() error {
+	err := w.stmt.Close()
+	_ = w.conn.Close()
+	return err
+}
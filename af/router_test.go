@@ -0,0 +1,111 @@
+package af
+
+import (
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/common/stmt"
+	"github.com/taosdata/driver-go/v3/errors"
+)
+
+type mockVGroupWriter struct {
+	lock   *sync.Mutex
+	tables map[int][]string
+	vgID   int
+	closed bool
+}
+
+func (w *mockVGroupWriter) Write(binds []*stmt.TaosStmt2BindData) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, bind := range binds {
+		w.tables[w.vgID] = append(w.tables[w.vgID], bind.TableName)
+	}
+	if w.vgID == 3 {
+		return &errors.TaosError{Code: 0xffff, ErrStr: "write error"}
+	}
+	return nil
+}
+
+func (w *mockVGroupWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestVGroupRouter(t *testing.T) {
+	lock := &sync.Mutex{}
+	tables := map[int][]string{}
+	var writers []*mockVGroupWriter
+	router := NewVGroupRouter(nil, "db", func(vgID int) (VGroupWriter, error) {
+		w := &mockVGroupWriter{lock: lock, tables: tables, vgID: vgID}
+		writers = append(writers, w)
+		return w, nil
+	})
+	// the cached tables are not looked up by the connection
+	router.vgIDs = map[string]int{"t1": 1, "t2": 2, "t3": 1, "t4": 3}
+	partitions, err := router.Partition([]string{"t1", "t2", "t3"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[int][]int{1: {0, 2}, 2: {1}}, partitions)
+	err = router.Write([]*stmt.TaosStmt2BindData{{TableName: "t1"}, {TableName: "t2"}, {TableName: "t3"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[int][]string{1: {"t1", "t3"}, 2: {"t2"}}, tables)
+	assert.Equal(t, 2, len(writers))
+	err = router.Write([]*stmt.TaosStmt2BindData{{TableName: "t4"}, {TableName: "t1"}})
+	assert.Error(t, err)
+	assert.Equal(t, 3, len(writers))
+	assert.NoError(t, router.Close())
+	for _, w := range writers {
+		assert.True(t, w.closed)
+	}
+	err = router.Write([]*stmt.TaosStmt2BindData{{TableName: "t1"}})
+	assert.Error(t, err)
+}
+
+func TestVGroupRouterWriterError(t *testing.T) {
+	lock := &sync.Mutex{}
+	tables := map[int][]string{}
+	router := NewVGroupRouter(nil, "db", func(vgID int) (VGroupWriter, error) {
+		if vgID == 4 {
+			return nil, &errors.TaosError{Code: 0xffff, ErrStr: "new writer error"}
+		}
+		return &mockVGroupWriter{lock: lock, tables: tables, vgID: vgID}, nil
+	})
+	router.vgIDs = map[string]int{"t1": 1, "t5": 4}
+	// no vgroup is written when a writer can not be created
+	err := router.Write([]*stmt.TaosStmt2BindData{{TableName: "t1"}, {TableName: "t5"}})
+	assert.Error(t, err)
+	assert.Empty(t, tables)
+	assert.NoError(t, router.Close())
+}
+
+func TestGetTablesVGroupIDs(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		_ = db.Close()
+	}()
+	_, err := db.Exec("create stable if not exists test_af.st_vg (ts timestamp, v int) tags (t int)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = db.Exec("create table if not exists test_af.ct_vg0 using test_af.st_vg tags (0) test_af.ct_vg1 using test_af.st_vg tags (1)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	vgIDs, err := db.GetTablesVGroupIDs("test_af", []string{"ct_vg0", "ct_vg1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i, table := range []string{"ct_vg0", "ct_vg1"} {
+		vgID, err := db.GetTableVGroupID("test_af", table)
+		assert.NoError(t, err)
+		assert.Equal(t, vgID, vgIDs[i])
+	}
+	_, err = (&Connector{}).GetTablesVGroupIDs("test_af", []string{"ct_vg0"})
+	assert.Equal(t, driver.ErrBadConn, err)
+}
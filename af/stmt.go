@@ -2,6 +2,7 @@ package af
 
 import "C"
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"unsafe"
@@ -11,32 +12,40 @@ import (
 	"github.com/taosdata/driver-go/v3/common/param"
 	taosError "github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
 type Stmt struct {
 	stmt       unsafe.Pointer
 	isInsert   bool
 	paramCount int
+	locker     *thread.Locker
 }
 
 func NewStmt(taosConn unsafe.Pointer) *Stmt {
-	locker.Lock()
-	stmt := wrapper.TaosStmtInit(taosConn)
-	locker.Unlock()
-	return &Stmt{stmt: stmt}
+	return newStmt(taosConn, 0, locker.Default())
 }
 
 func NewStmtWithReqID(taosConn unsafe.Pointer, reqID int64) *Stmt {
-	locker.Lock()
-	stmt := wrapper.TaosStmtInitWithReqID(taosConn, reqID)
-	locker.Unlock()
-	return &Stmt{stmt: stmt}
+	return newStmt(taosConn, reqID, locker.Default())
+}
+
+func newStmt(taosConn unsafe.Pointer, reqID int64, l *thread.Locker) *Stmt {
+	var stmt unsafe.Pointer
+	l.Lock()
+	if reqID == 0 {
+		stmt = wrapper.TaosStmtInit(taosConn)
+	} else {
+		stmt = wrapper.TaosStmtInitWithReqID(taosConn, reqID)
+	}
+	l.Unlock()
+	return &Stmt{stmt: stmt, locker: l}
 }
 
 func (s *Stmt) Prepare(sql string) error {
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmtPrepare(s.stmt, sql)
-	locker.Unlock()
+	s.locker.Unlock()
 	if code != 0 {
 		return s.stmtErr(code)
 	}
@@ -57,9 +66,9 @@ func (s *Stmt) NumParams() (int, error) {
 }
 
 func (s *Stmt) SetTableNameWithTags(tableName string, tags *param.Param) error {
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmtSetTBNameTags(s.stmt, tableName, tags.GetValues())
-	locker.Unlock()
+	s.locker.Unlock()
 	if code != 0 {
 		return s.stmtErr(code)
 	}
@@ -67,9 +76,9 @@ func (s *Stmt) SetTableNameWithTags(tableName string, tags *param.Param) error {
 }
 
 func (s *Stmt) SetTableName(tableName string) error {
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmtSetTBName(s.stmt, tableName)
-	locker.Unlock()
+	s.locker.Unlock()
 	if code != 0 {
 		return s.stmtErr(code)
 	}
@@ -93,9 +102,9 @@ func (s *Stmt) BindRow(row *param.Param) error {
 	if s.isInsert && len(value) != s.paramCount {
 		return fmt.Errorf("row param count error : expect %d got %d", s.paramCount, len(value))
 	}
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmtBindParam(s.stmt, value)
-	locker.Unlock()
+	s.locker.Unlock()
 	if code != 0 {
 		return s.stmtErr(code)
 	}
@@ -110,9 +119,9 @@ func (s *Stmt) GetAffectedRows() int {
 }
 
 func (s *Stmt) AddBatch() error {
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmtAddBatch(s.stmt)
-	locker.Unlock()
+	s.locker.Unlock()
 	if code != 0 {
 		return s.stmtErr(code)
 	}
@@ -120,9 +129,9 @@ func (s *Stmt) AddBatch() error {
 }
 
 func (s *Stmt) Execute() error {
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmtExecute(s.stmt)
-	locker.Unlock()
+	s.locker.Unlock()
 	if code != 0 {
 		return s.stmtErr(code)
 	}
@@ -130,9 +139,9 @@ func (s *Stmt) Execute() error {
 }
 
 func (s *Stmt) UseResult() (driver.Rows, error) {
-	locker.Lock()
+	s.locker.Lock()
 	res := wrapper.TaosStmtUseResult(s.stmt)
-	locker.Unlock()
+	s.locker.Unlock()
 	numFields := wrapper.TaosNumFields(res)
 	rowsHeader, err := wrapper.ReadColumn(res, numFields)
 	if err != nil {
@@ -141,19 +150,21 @@ func (s *Stmt) UseResult() (driver.Rows, error) {
 	h := async.GetHandler()
 	precision := wrapper.TaosResultPrecision(res)
 	rs := &rows{
+		ctx:        context.Background(),
 		handler:    h,
 		rowsHeader: rowsHeader,
 		result:     res,
 		precision:  precision,
 		isStmt:     true,
+		locker:     s.locker,
 	}
 	return rs, nil
 }
 
 func (s *Stmt) Close() error {
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmtClose(s.stmt)
-	locker.Unlock()
+	s.locker.Unlock()
 	s.stmt = nil
 	if code != 0 {
 		return s.stmtErr(code)
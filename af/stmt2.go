@@ -1,6 +1,7 @@
 package af
 
 import (
+	"context"
 	"database/sql/driver"
 	"errors"
 	"fmt"
@@ -12,6 +13,8 @@ import (
 	taosError "github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/wrapper"
 	"github.com/taosdata/driver-go/v3/wrapper/cgo"
+	"github.com/taosdata/driver-go/v3/wrapper/handler"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
 type Stmt2 struct {
@@ -24,75 +27,43 @@ type Stmt2 struct {
 	affectedRows int
 	queryResult  unsafe.Pointer
 	handle       cgo.Handle
+	locker       *thread.Locker
 }
 
-type Stmt2Result struct {
-	Res      unsafe.Pointer
-	Affected int
-	N        int
-}
+type Stmt2Result = handler.Stmt2Result
 
-type Stmt2CallBackCaller struct {
-	ExecResult chan *Stmt2Result
-}
+type Stmt2CallBackCaller = handler.Stmt2CallBackCaller
 
-type Stmt2CallBackCallerPool struct {
-	pool chan cgo.Handle
-}
+type Stmt2CallBackCallerPool = handler.Stmt2CallBackCallerPool
 
-const Stmt2CBPoolSize = 10000
+const Stmt2CBPoolSize = handler.Stmt2CBPoolSize
 
 func NewStmt2CallBackCallerPool(size int) *Stmt2CallBackCallerPool {
-	return &Stmt2CallBackCallerPool{
-		pool: make(chan cgo.Handle, size),
-	}
-}
-
-func (p *Stmt2CallBackCallerPool) Get() (cgo.Handle, *Stmt2CallBackCaller) {
-	select {
-	case h := <-p.pool:
-		return h, h.Value().(*Stmt2CallBackCaller)
-	default:
-		c := &Stmt2CallBackCaller{
-			ExecResult: make(chan *Stmt2Result, 1),
-		}
-		return cgo.NewHandle(c), c
-	}
+	return handler.NewStmt2CallBackCallerPool(size)
 }
 
-func (p *Stmt2CallBackCallerPool) Put(h cgo.Handle) {
-	select {
-	case p.pool <- h:
-	default:
-		h.Delete()
-	}
-}
+var GlobalStmt2CallBackCallerPool = handler.GlobalStmt2CallBackCallerPool
 
-func (s *Stmt2CallBackCaller) ExecCall(res unsafe.Pointer, affected int, code int) {
-	s.ExecResult <- &Stmt2Result{
-		Res:      res,
-		Affected: affected,
-		N:        code,
-	}
+func NewStmt2(taosConn unsafe.Pointer, reqID int64, singleTableBindOnce bool) *Stmt2 {
+	return newStmt2(taosConn, reqID, singleTableBindOnce, locker.Default())
 }
 
-var GlobalStmt2CallBackCallerPool = NewStmt2CallBackCallerPool(Stmt2CBPoolSize)
-
-func NewStmt2(taosConn unsafe.Pointer, reqID int64, singleTableBindOnce bool) *Stmt2 {
+func newStmt2(taosConn unsafe.Pointer, reqID int64, singleTableBindOnce bool, l *thread.Locker) *Stmt2 {
 	handle, caller := GlobalStmt2CallBackCallerPool.Get()
-	locker.Lock()
+	l.Lock()
 	stmt2 := wrapper.TaosStmt2Init(taosConn, reqID, true, singleTableBindOnce, handle)
-	locker.Unlock()
+	l.Unlock()
 	return &Stmt2{
 		stmt2:  stmt2,
 		handle: handle,
 		caller: caller,
+		locker: l,
 	}
 }
 
 func (s *Stmt2) Prepare(sql string) error {
-	locker.Lock()
-	defer locker.Unlock()
+	s.locker.Lock()
+	defer s.locker.Unlock()
 	code := wrapper.TaosStmt2Prepare(s.stmt2, sql)
 	if code != 0 {
 		return fmt.Errorf("prepare stmt2 error:%s, sql:%s", wrapper.TaosStmt2Error(s.stmt2), sql)
@@ -140,8 +111,8 @@ func (s *Stmt2) Bind(params []*stmt.TaosStmt2BindData) error {
 	if s.isInsert == nil {
 		return errors.New("stmt2 is not prepared")
 	}
-	locker.Lock()
-	defer locker.Unlock()
+	s.locker.Lock()
+	defer s.locker.Unlock()
 	err := wrapper.TaosStmt2BindParam(s.stmt2, *s.isInsert, params, s.fields, -1)
 	return err
 }
@@ -150,9 +121,9 @@ func (s *Stmt2) Execute() error {
 	if s.isInsert == nil {
 		return errors.New("stmt2 is not prepared")
 	}
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmt2Exec(s.stmt2)
-	locker.Unlock()
+	s.locker.Unlock()
 	if code != 0 {
 		return s.stmt2Err(code)
 	}
@@ -181,11 +152,13 @@ func (s *Stmt2) UseResult() (driver.Rows, error) {
 	h := async.GetHandler()
 	precision := wrapper.TaosResultPrecision(s.queryResult)
 	rs := &rows{
+		ctx:        context.Background(),
 		handler:    h,
 		rowsHeader: rowsHeader,
 		result:     s.queryResult,
 		precision:  precision,
 		isStmt:     true,
+		locker:     s.locker,
 	}
 	return rs, nil
 }
@@ -194,9 +167,9 @@ func (s *Stmt2) Close() error {
 	if s.stmt2 == nil {
 		return nil
 	}
-	locker.Lock()
+	s.locker.Lock()
 	code := wrapper.TaosStmt2Close(s.stmt2)
-	locker.Unlock()
+	s.locker.Unlock()
 	s.stmt2 = nil
 	GlobalStmt2CallBackCallerPool.Put(s.handle)
 	if code != 0 {
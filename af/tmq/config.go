@@ -8,7 +8,8 @@ import (
 )
 
 type config struct {
-	cConfig unsafe.Pointer
+	cConfig         unsafe.Pointer
+	rebalanceEvents bool
 }
 
 func newConfig() *config {
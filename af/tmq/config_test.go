@@ -3,6 +3,8 @@ package tmq
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/common/tmq"
 	"github.com/taosdata/driver-go/v3/wrapper"
 )
 
@@ -22,3 +24,19 @@ func TestList(t *testing.T) {
 	wrapper.TMQListAppend(topicList, "123")
 	wrapper.TMQListDestroy(topicList)
 }
+
+func TestConfigRebalanceEvents(t *testing.T) {
+	conf, err := configMapToConfig(&tmq.ConfigMap{
+		"group.id":                   "test",
+		tmq.RebalanceEventsEnableKey: "true",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, conf.rebalanceEvents)
+	conf.destroy()
+	_, err = configMapToConfig(&tmq.ConfigMap{
+		tmq.RebalanceEventsEnableKey: "wrong",
+	})
+	assert.Error(t, err)
+}
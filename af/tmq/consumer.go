@@ -2,9 +2,9 @@ package tmq
 
 import (
 	"errors"
+	"strconv"
 	"unsafe"
 
-	jsoniter "github.com/json-iterator/go"
 	"github.com/taosdata/driver-go/v3/common"
 	"github.com/taosdata/driver-go/v3/common/parser"
 	"github.com/taosdata/driver-go/v3/common/tmq"
@@ -15,6 +15,7 @@ import (
 type Consumer struct {
 	cConsumer  unsafe.Pointer
 	dataParser *parser.TMQRawDataParser
+	rebalancer *tmq.Rebalancer
 }
 
 // NewConsumer Create new TMQ consumer with TMQ config
@@ -31,6 +32,7 @@ func NewConsumer(conf *tmq.ConfigMap) (*Consumer, error) {
 	consumer := &Consumer{
 		cConsumer:  cConsumer,
 		dataParser: parser.NewTMQRawDataParser(),
+		rebalancer: tmq.NewRebalancer(nil, confStruct.rebalanceEvents),
 	}
 	return consumer, nil
 }
@@ -44,6 +46,15 @@ func configMapToConfig(m *tmq.ConfigMap) (*config, error) {
 			c.destroy()
 			return nil, errors.New("config value requires string")
 		}
+		if k == tmq.RebalanceEventsEnableKey {
+			enable, err := strconv.ParseBool(vv)
+			if err != nil {
+				c.destroy()
+				return nil, err
+			}
+			c.rebalanceEvents = enable
+			continue
+		}
 		err := c.setConfig(k, vv)
 		if err != nil {
 			c.destroy()
@@ -53,7 +64,20 @@ func configMapToConfig(m *tmq.ConfigMap) (*config, error) {
 	return c, nil
 }
 
-type RebalanceCb func(*Consumer, tmq.Event) error
+// RebalanceCb is the rebalance callback shared with ws/tmq
+type RebalanceCb = tmq.RebalanceCb
+
+var _ tmq.Consumer = (*Consumer)(nil)
+
+func init() {
+	tmq.RegisterConsumer(tmq.ConnectTypeNative, func(conf *tmq.ConfigMap) (tmq.Consumer, error) {
+		consumer, err := NewConsumer(conf)
+		if err != nil {
+			return nil, err
+		}
+		return consumer, nil
+	})
+}
 
 func (c *Consumer) Subscribe(topic string, rebalanceCb RebalanceCb) error {
 	return c.SubscribeTopics([]string{topic}, rebalanceCb)
@@ -72,20 +96,29 @@ func (c *Consumer) SubscribeTopics(topics []string, rebalanceCb RebalanceCb) err
 	if errCode != 0 {
 		return tmqError(errCode)
 	}
+	c.rebalancer.Subscribe(rebalanceCb)
 	return nil
 }
 
-// Unsubscribe TMQ unsubscribe
+// Unsubscribe TMQ unsubscribe, the assigned partitions are revoked before unsubscribing.
+// The consumer is unsubscribed even if the rebalance callback fails, the error of the callback is returned then.
 func (c *Consumer) Unsubscribe() error {
+	err := c.rebalancer.RevokeAll(c)
 	errCode := wrapper.TMQUnsubscribe(c.cConsumer)
 	if errCode != taosError.SUCCESS {
 		return tmqError(errCode)
 	}
-	return nil
+	c.rebalancer.Unsubscribed()
+	return err
 }
 
-// Poll consumer poll message with timeout
+// Poll consumer poll message with timeout. The assignment is checked before polling, when it changes
+// AssignedPartitions and RevokedPartitions are passed to the rebalance callback, or returned
+// if there is no callback and rebalance.events.enable is true.
 func (c *Consumer) Poll(timeoutMs int) tmq.Event {
+	if event := c.rebalancer.Poll(c); event != nil {
+		return event
+	}
 	message := wrapper.TMQConsumerPoll(c.cConsumer, int64(timeoutMs))
 	if message == nil {
 		return nil
@@ -165,11 +198,7 @@ func (c *Consumer) getMeta(message unsafe.Pointer) (*tmq.Meta, error) {
 	if p != nil {
 		data := wrapper.ParseJsonMeta(p)
 		wrapper.TMQFreeJsonMeta(p)
-		err := jsoniter.Unmarshal(data, &meta)
-		if err != nil {
-			return nil, err
-		}
-		return &meta, nil
+		return tmq.ParseMeta(data)
 	}
 	return &meta, nil
 }
@@ -188,14 +217,11 @@ func (c *Consumer) getData(message unsafe.Pointer) ([]*tmq.Data, error) {
 	}
 	var tmqData []*tmq.Data
 	for i := 0; i < len(blockInfos); i++ {
-		data, err := parser.ReadBlockSimple(blockInfos[i].RawBlock, blockInfos[i].Precision)
+		data, err := parser.ReadTMQData(blockInfos[i])
 		if err != nil {
 			return nil, err
 		}
-		tmqData = append(tmqData, &tmq.Data{
-			TableName: blockInfos[i].TableName,
-			Data:      data,
-		})
+		tmqData = append(tmqData, data)
 	}
 	return tmqData, nil
 }
@@ -288,13 +314,14 @@ func (c *Consumer) Position(partitions []tmq.TopicPartition) (offsets []tmq.Topi
 	return
 }
 
-// Close release consumer
+// Close release consumer, the assigned partitions are revoked by the rebalance callback before closing
 func (c *Consumer) Close() error {
+	err := c.rebalancer.RevokeAll(c)
 	errCode := wrapper.TMQConsumerClose(c.cConsumer)
 	if errCode != 0 {
 		return tmqError(errCode)
 	}
-	return nil
+	return err
 }
 
 func tmqError(errCode int32) error {
@@ -0,0 +1,94 @@
+package tmq
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/taosdata/driver-go/v3/common/tmq"
+	taosError "github.com/taosdata/driver-go/v3/errors"
+	"github.com/taosdata/driver-go/v3/wrapper"
+)
+
+// RawMessage is an opaque message polled by PollRaw, it holds the data or the schema change in the raw format
+// and can be written into another cluster by af.Connector.WriteRaw. Free must be called after the message is used.
+type RawMessage struct {
+	TopicPartition tmq.TopicPartition
+	dbName         string
+	topic          string
+	offset         tmq.Offset
+	resultType     int32
+	message        unsafe.Pointer
+	raw            unsafe.Pointer
+}
+
+func (m *RawMessage) String() string {
+	return fmt.Sprintf("RawMessage: %s[%s]:%d", m.topic, m.dbName, m.offset)
+}
+
+func (m *RawMessage) Topic() string {
+	return m.topic
+}
+
+func (m *RawMessage) DBName() string {
+	return m.dbName
+}
+
+func (m *RawMessage) Offset() tmq.Offset {
+	return m.offset
+}
+
+// Type returns the type of the message, common.TMQ_RES_DATA, common.TMQ_RES_TABLE_META or common.TMQ_RES_METADATA.
+func (m *RawMessage) Type() int32 {
+	return m.resultType
+}
+
+// Raw returns the tmq_raw_data of the message, it is invalid after Free.
+func (m *RawMessage) Raw() unsafe.Pointer {
+	return m.raw
+}
+
+// Free releases the message.
+func (m *RawMessage) Free() {
+	if m.raw != nil {
+		wrapper.TMQFreeRaw(m.raw)
+		m.raw = nil
+	}
+	if m.message != nil {
+		wrapper.TaosFreeResult(m.message)
+		m.message = nil
+	}
+}
+
+// PollRaw consumer poll message with timeout in raw mode, the message is returned as *RawMessage without decoding.
+// To commit the offsets only after the messages are written, set enable.auto.commit to false
+// and commit the TopicPartition of the message by CommitOffsets after af.Connector.WriteRaw succeeds.
+func (c *Consumer) PollRaw(timeoutMs int) tmq.Event {
+	if event := c.rebalancer.Poll(c); event != nil {
+		return event
+	}
+	message := wrapper.TMQConsumerPoll(c.cConsumer, int64(timeoutMs))
+	if message == nil {
+		return nil
+	}
+	errCode, raw := wrapper.TMQGetRaw(message)
+	if errCode != taosError.SUCCESS {
+		errStr := wrapper.TaosErrorStr(message)
+		wrapper.TaosFreeResult(message)
+		return tmq.NewTMQError(int(errCode), errStr)
+	}
+	topic := wrapper.TMQGetTopicName(message)
+	offset := tmq.Offset(wrapper.TMQGetVgroupOffset(message))
+	return &RawMessage{
+		TopicPartition: tmq.TopicPartition{
+			Topic:     &topic,
+			Partition: wrapper.TMQGetVgroupID(message),
+			Offset:    offset,
+		},
+		dbName:     wrapper.TMQGetDBName(message),
+		topic:      topic,
+		offset:     offset,
+		resultType: wrapper.TMQGetResType(message),
+		message:    message,
+		raw:        raw,
+	}
+}
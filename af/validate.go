@@ -0,0 +1,87 @@
+package af
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/taosdata/driver-go/v3/errors"
+	"github.com/taosdata/driver-go/v3/wrapper"
+)
+
+// SQLError is returned by ValidateSQL when the sql is invalid.
+type SQLError struct {
+	Code   int32
+	ErrStr string
+	// Position is the byte offset in the sql where the error is reported, -1 when the error has no position.
+	Position int
+}
+
+func (e *SQLError) Error() string {
+	if e.Position < 0 {
+		return fmt.Sprintf("[0x%x] %s", e.Code, e.ErrStr)
+	}
+	return fmt.Sprintf("[0x%x] %s at position %d", e.Code, e.ErrStr, e.Position)
+}
+
+// Unwrap returns the error as *errors.TaosError.
+func (e *SQLError) Unwrap() error {
+	return &errors.TaosError{Code: e.Code, ErrStr: e.ErrStr}
+}
+
+// ValidateSQL Parse the sql without executing it, *SQLError is returned when the sql is invalid
+func (conn *Connector) ValidateSQL(sql string) error {
+	if conn.taos == nil {
+		return driver.ErrBadConn
+	}
+	conn.locker.Lock()
+	code := wrapper.TaosValidateSql(conn.taos, sql)
+	if code == 0 {
+		conn.locker.Unlock()
+		return nil
+	}
+	errStr := wrapper.TaosErrorStr(nil)
+	conn.locker.Unlock()
+	return &SQLError{
+		Code:     int32(code) & 0xffff,
+		ErrStr:   errStr,
+		Position: errorPosition(sql, errStr),
+	}
+}
+
+// errorPosition finds the position of the fragment in the error like `syntax error near "from t"`.
+func errorPosition(sql, errStr string) int {
+	index := strings.Index(errStr, `near "`)
+	if index < 0 {
+		return -1
+	}
+	fragment := errStr[index+len(`near "`):]
+	end := strings.LastIndexByte(fragment, '"')
+	if end < 0 {
+		return -1
+	}
+	fragment = fragment[:end]
+	if len(fragment) == 0 {
+		return -1
+	}
+	return strings.Index(sql, fragment)
+}
+
+// PreloadTableMeta Load the meta of the tables into the client cache, the table names can be qualified by the database name
+func (conn *Connector) PreloadTableMeta(tables []string) error {
+	if conn.taos == nil {
+		return driver.ErrBadConn
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+	conn.locker.Lock()
+	code := wrapper.TaosLoadTableInfo(conn.taos, tables)
+	if code == 0 {
+		conn.locker.Unlock()
+		return nil
+	}
+	errStr := wrapper.TaosErrorStr(nil)
+	conn.locker.Unlock()
+	return errors.NewError(code, errStr)
+}
@@ -0,0 +1,59 @@
+package af
+
+import (
+	stdErrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/errors"
+)
+
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		errStr string
+		want   int
+	}{
+		{name: "near", sql: "slect 1", errStr: `syntax error near "slect 1"`, want: 0},
+		{name: "middle", sql: "select * fro t", errStr: `syntax error near "fro t"`, want: 9},
+		{name: "no position", sql: "select * from t", errStr: "Table does not exist", want: -1},
+		{name: "empty fragment", sql: "select", errStr: `syntax error near ""`, want: -1},
+		{name: "not found", sql: "select", errStr: `syntax error near "x"`, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorPosition(tt.sql, tt.errStr))
+		})
+	}
+}
+
+func TestValidateSQL(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		_ = db.Close()
+	}()
+	assert.NoError(t, db.ValidateSQL("show databases"))
+	err := db.ValidateSQL("slect 1")
+	var sqlErr *SQLError
+	if !assert.True(t, stdErrors.As(err, &sqlErr)) {
+		return
+	}
+	assert.Equal(t, int32(0x2600), sqlErr.Code)
+	assert.Equal(t, 0, sqlErr.Position)
+	var taosErr *errors.TaosError
+	assert.True(t, stdErrors.As(err, &taosErr))
+}
+
+func TestPreloadTableMeta(t *testing.T) {
+	db := testDatabase(t)
+	defer func() {
+		_ = db.Close()
+	}()
+	_, err := db.Exec("create table if not exists test_af.t_preload(ts timestamp, v int)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, db.PreloadTableMeta([]string{"test_af.t_preload"}))
+	assert.NoError(t, db.PreloadTableMeta(nil))
+}
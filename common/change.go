@@ -1,6 +1,7 @@
 package common
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"time"
 )
@@ -32,3 +33,15 @@ func TimeToTimestamp(t time.Time, precision int) (timestamp int64) {
 		panic(s)
 	}
 }
+
+// RowTimeIn converts the time.Time values of the row into loc, the row is unchanged when loc is nil.
+func RowTimeIn(row []driver.Value, loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	for i, v := range row {
+		if t, ok := v.(time.Time); ok {
+			row[i] = t.In(loc)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"database/sql/driver"
 	"reflect"
 	"testing"
 	"time"
@@ -99,3 +100,24 @@ func TestTimeToTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestRowTimeIn(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2022, 01, 25, 0, 0, 0, 0, time.UTC)
+	row := []driver.Value{ts, int64(1), nil}
+	RowTimeIn(row, nil)
+	if row[0].(time.Time).Location() != time.UTC {
+		t.Errorf("RowTimeIn() with nil location changed the row: %v", row[0])
+	}
+	RowTimeIn(row, shanghai)
+	got := row[0].(time.Time)
+	if got.Location() != shanghai || !got.Equal(ts) {
+		t.Errorf("RowTimeIn() = %v, want %v", got, ts.In(shanghai))
+	}
+	if row[1] != int64(1) || row[2] != nil {
+		t.Errorf("RowTimeIn() changed non time values: %v", row[1:])
+	}
+}
@@ -24,6 +24,14 @@ const (
 	TSDB_OPTION_USE_ADAPTER
 )
 
+const (
+	TSDB_OPTION_CONNECTION_CLEAR = iota - 1
+	TSDB_OPTION_CONNECTION_CHARSET
+	TSDB_OPTION_CONNECTION_TIMEZONE
+	TSDB_OPTION_CONNECTION_USER_IP
+	TSDB_OPTION_CONNECTION_USER_APP
+)
+
 const (
 	TMQ_RES_INVALID    = -1
 	TMQ_RES_DATA       = 1
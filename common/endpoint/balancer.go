@@ -0,0 +1,242 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	taosErrors "github.com/taosdata/driver-go/v3/errors"
+)
+
+// Policies to choose the endpoint to connect.
+const (
+	// RoundRobin chooses the endpoints in turn.
+	RoundRobin = "roundRobin"
+	// Random chooses the endpoints randomly.
+	Random = "random"
+	// FirstAvailable chooses the first healthy endpoint in the configured order.
+	FirstAvailable = "firstAvailable"
+)
+
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+)
+
+var ErrNoEndpoint = errors.New("no endpoint")
+
+var (
+	ErrInvalidAddr = &taosErrors.TaosError{Code: 0xffff, ErrStr: "invalid DSN: network address not terminated (missing closing brace)"}
+	ErrInvalidPort = &taosErrors.TaosError{Code: 0xffff, ErrStr: "invalid DSN: network port is not a valid number"}
+)
+
+type endpointState struct {
+	addr     string
+	failures int
+	retryAt  time.Time
+}
+
+// Balancer chooses endpoints by policy, an endpoint failed to connect is skipped with an exponential backoff
+// until it is marked as success again. It is safe for concurrent use.
+type Balancer struct {
+	lock       sync.Mutex
+	policy     string
+	endpoints  []*endpointState
+	next       int
+	rand       *rand.Rand
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	now        func() time.Time
+}
+
+// NewBalancer creates a Balancer of the addresses, an empty policy means RoundRobin.
+func NewBalancer(addrs []string, policy string) (*Balancer, error) {
+	if len(addrs) == 0 {
+		return nil, ErrNoEndpoint
+	}
+	if policy == "" {
+		policy = RoundRobin
+	}
+	if !IsValidPolicy(policy) {
+		return nil, fmt.Errorf("invalid endpoint policy: %s", policy)
+	}
+	endpoints := make([]*endpointState, len(addrs))
+	for i, addr := range addrs {
+		endpoints[i] = &endpointState{addr: addr}
+	}
+	return &Balancer{
+		policy:     policy,
+		endpoints:  endpoints,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		now:        time.Now,
+	}, nil
+}
+
+// IsValidPolicy reports whether the policy is supported.
+func IsValidPolicy(policy string) bool {
+	switch policy {
+	case RoundRobin, Random, FirstAvailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// SplitHosts splits the comma separated host list, blank items are ignored.
+func SplitHosts(hosts string) []string {
+	list := strings.Split(hosts, ",")
+	result := make([]string, 0, len(list))
+	for _, host := range list {
+		host = strings.TrimSpace(host)
+		if host != "" {
+			result = append(result, host)
+		}
+	}
+	return result
+}
+
+// SplitURL splits the url whose host is a comma separated list, such as ws://host1:6041,host2:6041/ws,
+// into the urls of each host.
+func SplitURL(u *url.URL) []string {
+	hosts := SplitHosts(u.Host)
+	urls := make([]string, len(hosts))
+	for i, host := range hosts {
+		hostUrl := *u
+		hostUrl.Host = host
+		urls[i] = hostUrl.String()
+	}
+	return urls
+}
+
+// ParseEndpoints checks each address of the list is formatted as host:port, an IPv6 host is enclosed in
+// square brackets such as [::1]:6041, port 0 means the default port.
+func ParseEndpoints(addrList []string) ([]string, error) {
+	endpoints := make([]string, len(addrList))
+	for i, addr := range addrList {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || len(host) == 0 {
+			return nil, ErrInvalidAddr
+		}
+		if _, err = strconv.Atoi(port); err != nil {
+			return nil, ErrInvalidPort
+		}
+		endpoints[i] = addr
+	}
+	return endpoints, nil
+}
+
+// FillDefaultPort replaces the port 0 or the missing port of the addresses with the default port.
+func FillDefaultPort(addrs []string, port int) []string {
+	defaultPort := strconv.Itoa(port)
+	result := make([]string, len(addrs))
+	for i, addr := range addrs {
+		host, p, err := net.SplitHostPort(addr)
+		if err != nil {
+			// the address has no port
+			host = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+			p = ""
+		}
+		if p == "" || p == "0" {
+			p = defaultPort
+		}
+		result[i] = net.JoinHostPort(host, p)
+	}
+	return result
+}
+
+// Candidates returns all the endpoints in the order to try, healthy endpoints ordered by policy come first,
+// the endpoints in backoff follow ordered by their retry time.
+func (b *Balancer) Candidates() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	count := len(b.endpoints)
+	start := 0
+	if b.policy == RoundRobin {
+		start = b.next % count
+		b.next = (b.next + 1) % count
+	}
+	healthy := make([]string, 0, count)
+	var unhealthy []*endpointState
+	for i := 0; i < count; i++ {
+		e := b.endpoints[(start+i)%count]
+		if e.retryAt.After(now) {
+			unhealthy = append(unhealthy, e)
+		} else {
+			healthy = append(healthy, e.addr)
+		}
+	}
+	if b.policy == Random {
+		b.rand.Shuffle(len(healthy), func(i, j int) {
+			healthy[i], healthy[j] = healthy[j], healthy[i]
+		})
+	}
+	for i := 1; i < len(unhealthy); i++ {
+		for j := i; j > 0 && unhealthy[j].retryAt.Before(unhealthy[j-1].retryAt); j-- {
+			unhealthy[j], unhealthy[j-1] = unhealthy[j-1], unhealthy[j]
+		}
+	}
+	for _, e := range unhealthy {
+		healthy = append(healthy, e.addr)
+	}
+	return healthy
+}
+
+// MarkFailed puts the endpoint into backoff, the backoff doubles with each consecutive failure.
+func (b *Balancer) MarkFailed(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, e := range b.endpoints {
+		if e.addr == addr {
+			e.failures++
+			backoff := b.MinBackoff
+			for i := 1; i < e.failures && backoff < b.MaxBackoff; i++ {
+				backoff *= 2
+			}
+			if backoff > b.MaxBackoff {
+				backoff = b.MaxBackoff
+			}
+			e.retryAt = b.now().Add(backoff)
+			return
+		}
+	}
+}
+
+// MarkSuccess resets the backoff of the endpoint.
+func (b *Balancer) MarkSuccess(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, e := range b.endpoints {
+		if e.addr == addr {
+			e.failures = 0
+			e.retryAt = time.Time{}
+			return
+		}
+	}
+}
+
+// Do calls fn with the candidates in turn until it succeeds. When fn fails and failover reports true
+// the endpoint is marked as failed and the next one is tried, otherwise the error is returned directly.
+func (b *Balancer) Do(fn func(addr string) error, failover func(err error) bool) error {
+	var err error
+	for _, addr := range b.Candidates() {
+		err = fn(addr)
+		if err == nil {
+			b.MarkSuccess(addr)
+			return nil
+		}
+		if failover != nil && !failover(err) {
+			return err
+		}
+		b.MarkFailed(addr)
+	}
+	return err
+}
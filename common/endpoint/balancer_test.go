@@ -0,0 +1,153 @@
+package endpoint
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBalancer(t *testing.T, policy string, now *time.Time) *Balancer {
+	b, err := NewBalancer([]string{"a:6041", "b:6041", "c:6041"}, policy)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func TestNewBalancer(t *testing.T) {
+	_, err := NewBalancer(nil, RoundRobin)
+	assert.Equal(t, ErrNoEndpoint, err)
+	_, err = NewBalancer([]string{"a:6041"}, "wrong")
+	assert.Error(t, err)
+	b, err := NewBalancer([]string{"a:6041"}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, RoundRobin, b.policy)
+}
+
+func TestRoundRobin(t *testing.T) {
+	now := time.Now()
+	b := newTestBalancer(t, RoundRobin, &now)
+	assert.Equal(t, []string{"a:6041", "b:6041", "c:6041"}, b.Candidates())
+	assert.Equal(t, []string{"b:6041", "c:6041", "a:6041"}, b.Candidates())
+	assert.Equal(t, []string{"c:6041", "a:6041", "b:6041"}, b.Candidates())
+	assert.Equal(t, []string{"a:6041", "b:6041", "c:6041"}, b.Candidates())
+}
+
+func TestFirstAvailable(t *testing.T) {
+	now := time.Now()
+	b := newTestBalancer(t, FirstAvailable, &now)
+	assert.Equal(t, []string{"a:6041", "b:6041", "c:6041"}, b.Candidates())
+	assert.Equal(t, []string{"a:6041", "b:6041", "c:6041"}, b.Candidates())
+	b.MarkFailed("a:6041")
+	assert.Equal(t, []string{"b:6041", "c:6041", "a:6041"}, b.Candidates())
+	b.MarkSuccess("a:6041")
+	assert.Equal(t, []string{"a:6041", "b:6041", "c:6041"}, b.Candidates())
+}
+
+func TestRandom(t *testing.T) {
+	now := time.Now()
+	b := newTestBalancer(t, Random, &now)
+	b.MarkFailed("b:6041")
+	for i := 0; i < 10; i++ {
+		candidates := b.Candidates()
+		assert.ElementsMatch(t, []string{"a:6041", "c:6041"}, candidates[:2])
+		assert.Equal(t, "b:6041", candidates[2])
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	now := time.Now()
+	b := newTestBalancer(t, FirstAvailable, &now)
+	b.MarkFailed("a:6041")
+	b.MarkFailed("b:6041")
+	b.MarkFailed("b:6041")
+	// unhealthy endpoints are tried last, the one recovers first comes first
+	assert.Equal(t, []string{"c:6041", "a:6041", "b:6041"}, b.Candidates())
+	now = now.Add(time.Second)
+	assert.Equal(t, []string{"a:6041", "c:6041", "b:6041"}, b.Candidates())
+	now = now.Add(time.Second)
+	assert.Equal(t, []string{"a:6041", "b:6041", "c:6041"}, b.Candidates())
+	for i := 0; i < 10; i++ {
+		b.MarkFailed("a:6041")
+	}
+	now = now.Add(DefaultMaxBackoff - time.Nanosecond)
+	assert.Equal(t, []string{"b:6041", "c:6041", "a:6041"}, b.Candidates())
+	now = now.Add(time.Nanosecond)
+	assert.Equal(t, []string{"a:6041", "b:6041", "c:6041"}, b.Candidates())
+}
+
+func TestDo(t *testing.T) {
+	now := time.Now()
+	b := newTestBalancer(t, FirstAvailable, &now)
+	var tried []string
+	err := b.Do(func(addr string) error {
+		tried = append(tried, addr)
+		if addr == "c:6041" {
+			return nil
+		}
+		return errors.New("dial error")
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a:6041", "b:6041", "c:6041"}, tried)
+	assert.Equal(t, []string{"c:6041", "a:6041", "b:6041"}, b.Candidates())
+
+	tried = nil
+	authErr := errors.New("auth error")
+	err = b.Do(func(addr string) error {
+		tried = append(tried, addr)
+		return authErr
+	}, func(err error) bool {
+		return err != authErr
+	})
+	assert.Equal(t, authErr, err)
+	assert.Equal(t, []string{"c:6041"}, tried)
+
+	dialErr := errors.New("dial error")
+	err = b.Do(func(addr string) error {
+		return dialErr
+	}, nil)
+	assert.Equal(t, dialErr, err)
+}
+
+func TestSplitURL(t *testing.T) {
+	u, err := url.Parse("ws://root:taosdata@a:6041,b:6041/ws?token=1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{
+		"ws://root:taosdata@a:6041/ws?token=1",
+		"ws://root:taosdata@b:6041/ws?token=1",
+	}, SplitURL(u))
+}
+
+func TestFillDefaultPort(t *testing.T) {
+	assert.Equal(t, []string{"a:6041", "b:6042", "c:6041"}, FillDefaultPort([]string{"a:0", "b:6042", "c"}, 6041))
+	assert.Equal(t,
+		[]string{"[::1]:6041", "[::1]:6042", "[fe80::1]:6041", "[::1]:6041"},
+		FillDefaultPort([]string{"[::1]:0", "[::1]:6042", "[fe80::1]", "::1"}, 6041),
+	)
+}
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints, err := ParseEndpoints([]string{"a:6041", "b:0"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a:6041", "b:0"}, endpoints)
+	_, err = ParseEndpoints([]string{"a:6041", "b"})
+	assert.Equal(t, ErrInvalidAddr, err)
+	_, err = ParseEndpoints([]string{":6041"})
+	assert.Equal(t, ErrInvalidAddr, err)
+	_, err = ParseEndpoints([]string{"a:port"})
+	assert.Equal(t, ErrInvalidPort, err)
+
+	endpoints, err = ParseEndpoints([]string{"[::1]:6041", "[fe80::1]:0"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"[::1]:6041", "[fe80::1]:0"}, endpoints)
+	_, err = ParseEndpoints([]string{"[::1]:6041", "::1"})
+	assert.Equal(t, ErrInvalidAddr, err)
+	_, err = ParseEndpoints([]string{"[::1]:port"})
+	assert.Equal(t, ErrInvalidPort, err)
+}
@@ -0,0 +1,29 @@
+package endpoint
+
+import (
+	"github.com/gorilla/websocket"
+)
+
+// DialError is the error of dialing an endpoint, a failover predicate can detect it to try the next endpoint.
+type DialError struct {
+	Err error
+}
+
+func (e *DialError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DialError) Unwrap() error {
+	return e.Err
+}
+
+// Dial dials the websocket url of the candidates in turn until one succeeds.
+func Dial(dialer *websocket.Dialer, balancer *Balancer) (*websocket.Conn, error) {
+	var conn *websocket.Conn
+	err := balancer.Do(func(addr string) error {
+		var err error
+		conn, _, err = dialer.Dial(addr, nil)
+		return err
+	}, nil)
+	return conn, err
+}
@@ -0,0 +1,45 @@
+package endpoint
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDial(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = conn.Close()
+	}))
+	defer server.Close()
+	good := "ws" + strings.TrimPrefix(server.URL, "http")
+	bad := "ws://127.0.0.1:1"
+	b, err := NewBalancer([]string{bad, good}, FirstAvailable)
+	if !assert.NoError(t, err) {
+		return
+	}
+	conn, err := Dial(&websocket.Dialer{}, b)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_ = conn.Close()
+	assert.Equal(t, []string{good, bad}, b.Candidates())
+}
+
+func TestDialError(t *testing.T) {
+	cause := errors.New("refused")
+	var err error = &DialError{Err: cause}
+	assert.Equal(t, "refused", err.Error())
+	assert.True(t, errors.Is(err, cause))
+	var dialErr *DialError
+	assert.True(t, errors.As(err, &dialErr))
+}
@@ -0,0 +1,35 @@
+package common
+
+import "strconv"
+
+// NotifyType is the type of the notification sent by the server to a connection.
+type NotifyType int
+
+const (
+	// NotifyPasswordChanged is sent when the password of the connected user is changed.
+	NotifyPasswordChanged NotifyType = TAOS_NOTIFY_PASSVER
+	// NotifyWhitelistChanged is sent when the ip whitelist of the connected user is changed.
+	NotifyWhitelistChanged NotifyType = TAOS_NOTIFY_WHITELIST_VER
+	// NotifyUserDropped is sent when the connected user is dropped, the connection can not be used anymore.
+	NotifyUserDropped NotifyType = TAOS_NOTIFY_USER_DROPPED
+)
+
+func (t NotifyType) String() string {
+	switch t {
+	case NotifyPasswordChanged:
+		return "password changed"
+	case NotifyWhitelistChanged:
+		return "whitelist changed"
+	case NotifyUserDropped:
+		return "user dropped"
+	default:
+		return "unknown notify type " + strconv.Itoa(int(t))
+	}
+}
+
+// NotifyEvent is a notification sent by the server to a connection.
+type NotifyEvent struct {
+	Type NotifyType
+	// Version is the new password version or whitelist version, it is 0 when the user is dropped.
+	Version int64
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"unsafe"
 
+	"github.com/taosdata/driver-go/v3/common"
 	"github.com/taosdata/driver-go/v3/common/pointer"
+	"github.com/taosdata/driver-go/v3/common/tmq"
 )
 
 type TMQRawDataParser struct {
@@ -23,6 +25,42 @@ type TMQBlockInfo struct {
 	TableName string
 }
 
+// Columns Read the column metadata of the block, the names are empty when the block is parsed without schema
+func (b *TMQBlockInfo) Columns() []*tmq.DataColumn {
+	colCount := int(RawBlockGetNumOfCols(b.RawBlock))
+	infos := make([]RawBlockColInfo, colCount)
+	RawBlockGetColInfo(b.RawBlock, infos)
+	columns := make([]*tmq.DataColumn, colCount)
+	for i := 0; i < colCount; i++ {
+		column := &tmq.DataColumn{
+			Type:  uint8(infos[i].ColType),
+			Bytes: int64(infos[i].Bytes),
+		}
+		if i < len(b.Schema) {
+			column.Name = b.Schema[i].Name
+		}
+		if column.Type == common.TSDB_DATA_TYPE_DECIMAL || column.Type == common.TSDB_DATA_TYPE_DECIMAL64 {
+			_, column.Precision, column.Scale = RawBlockGetDecimalInfo(b.RawBlock, i)
+		}
+		columns[i] = column
+	}
+	return columns
+}
+
+// ReadTMQData Read the rows and the column metadata of the block
+func ReadTMQData(blockInfo *TMQBlockInfo) (*tmq.Data, error) {
+	data, err := ReadBlockSimple(blockInfo.RawBlock, blockInfo.Precision)
+	if err != nil {
+		return nil, err
+	}
+	return &tmq.Data{
+		TableName: blockInfo.TableName,
+		Data:      data,
+		Precision: blockInfo.Precision,
+		Columns:   blockInfo.Columns(),
+	}, nil
+}
+
 type TMQRawDataSchema struct {
 	ColType uint8
 	Flag    int8
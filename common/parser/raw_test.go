@@ -8,6 +8,8 @@ import (
 	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/tmq"
 )
 
 func TestParse(t *testing.T) {
@@ -140,6 +142,18 @@ func TestParse(t *testing.T) {
 		},
 	}, blockInfos[0].Schema)
 	assert.Equal(t, "ctb0", blockInfos[0].TableName)
+	assert.Equal(t, []*tmq.DataColumn{
+		{Name: "ts", Type: common.TSDB_DATA_TYPE_TIMESTAMP, Bytes: 8},
+		{Name: "c1", Type: common.TSDB_DATA_TYPE_INT, Bytes: 4},
+		{Name: "c2", Type: common.TSDB_DATA_TYPE_FLOAT, Bytes: 4},
+		{Name: "c3", Type: common.TSDB_DATA_TYPE_BINARY, Bytes: 130},
+	}, blockInfos[0].Columns())
+	tmqData, err := ReadTMQData(blockInfos[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "ctb0", tmqData.TableName)
+	assert.Equal(t, 2, tmqData.Precision)
+	assert.Equal(t, blockInfos[0].Columns(), tmqData.Columns)
+	assert.Equal(t, 1, len(tmqData.Data))
 }
 
 func TestParseTwoBlock(t *testing.T) {
@@ -27,162 +27,262 @@ type TDEngineRestfulResp struct {
 
 var jsonI = jsoniter.ConfigCompatibleWithStandardLibrary
 
+// UnmarshalRestfulBody decodes the whole restful response including all the rows of data.
 func UnmarshalRestfulBody(body io.Reader, bufferSize int) (*TDEngineRestfulResp, error) {
-	var result TDEngineRestfulResp
+	decoder, err := NewRestfulDecoder(body, bufferSize)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+	result := decoder.Resp
+	for {
+		row := make([]driver.Value, len(result.ColTypes))
+		err = decoder.Next(row)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Data = append(result.Data, row)
+	}
+}
+
+const (
+	restfulDecodeHeader = iota
+	restfulDecodeData
+	restfulDecodeDone
+)
+
+// RestfulDecoder decodes the restful response as a stream. NewRestfulDecoder decodes the fields before data,
+// such as code, desc and column_meta, then the rows of data are decoded one by one when Next is called,
+// so the memory usage does not depend on the size of the result.
+type RestfulDecoder struct {
+	// Resp holds the fields of the response except Data, Rows is set after all the rows are decoded.
+	Resp  *TDEngineRestfulResp
+	iter  *jsoniter.Iterator
+	state int
+}
+
+// NewRestfulDecoder creates a RestfulDecoder reading from the body and decodes the fields before data.
+func NewRestfulDecoder(body io.Reader, bufferSize int) (*RestfulDecoder, error) {
 	iter := jsonI.BorrowIterator(make([]byte, bufferSize))
-	defer jsonI.ReturnIterator(iter)
 	iter.Reset(body)
-	timeFormat := time.RFC3339Nano
-	iter.ReadObjectCB(func(iter *jsoniter.Iterator, s string) bool {
-		switch s {
+	decoder := &RestfulDecoder{
+		Resp: &TDEngineRestfulResp{},
+		iter: iter,
+	}
+	err := decoder.readFields()
+	if err != nil {
+		decoder.Close()
+		return nil, err
+	}
+	return decoder, nil
+}
+
+// readFields reads the object fields until the data array or the end of the object.
+func (d *RestfulDecoder) readFields() error {
+	iter := d.iter
+	for field := iter.ReadObject(); field != ""; field = iter.ReadObject() {
+		switch field {
 		case "code":
-			result.Code = iter.ReadInt()
+			d.Resp.Code = iter.ReadInt()
 		case "desc":
-			result.Desc = iter.ReadString()
+			d.Resp.Desc = iter.ReadString()
 		case "column_meta":
-			iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
-				index := 0
-				isDecimal := false
-				iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
-					switch index {
-					case 0:
-						result.ColNames = append(result.ColNames, iter.ReadString())
-						index = 1
-					case 1:
-						typeStr := iter.ReadString()
-						if strings.HasPrefix(typeStr, "DECIMAL(") {
-							// parse DECIMAL(10,2) to DECIMAL, 10, 2
-							precision, scale, err := parseDecimalType(typeStr)
-							if err != nil {
-								iter.ReportError("parse decimal", err.Error())
-								return false
-							}
-							isDecimal = true
-							result.Precisions = append(result.Precisions, precision)
-							result.Scales = append(result.Scales, scale)
-						} else {
-							t, exist := NameTypeMap[typeStr]
-							if exist {
-								result.ColTypes = append(result.ColTypes, t)
-							} else {
-								iter.ReportError("unsupported type in column_meta", typeStr)
-							}
-							result.Precisions = append(result.Precisions, 0)
-							result.Scales = append(result.Scales, 0)
-						}
-						index = 2
-					case 2:
-						colLen := iter.ReadInt64()
-						result.ColLength = append(result.ColLength, colLen)
-						index = 0
-						if isDecimal {
-							switch colLen {
-							case 8:
-								result.ColTypes = append(result.ColTypes, TSDB_DATA_TYPE_DECIMAL64)
-							case 16:
-								result.ColTypes = append(result.ColTypes, TSDB_DATA_TYPE_DECIMAL)
-							default:
-								iter.ReportError("parse decimal", fmt.Sprintf("invalid length %d", colLen))
-								return false
-							}
-						}
-						isDecimal = false
-					}
-					return true
-				})
-				return true
-			})
+			readColumnMeta(iter, d.Resp)
 		case "data":
-			columnCount := len(result.ColTypes)
-			column := 0
-			iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
-				column = 0
-				var row = make([]driver.Value, columnCount)
-				iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
-					defer func() {
-						column += 1
-					}()
-					columnType := result.ColTypes[column]
-					if columnType == TSDB_DATA_TYPE_JSON {
-						row[column] = iter.SkipAndReturnBytes()
-						return true
+			d.state = restfulDecodeData
+			return d.error()
+		case "rows":
+			d.Resp.Rows = iter.ReadInt()
+		default:
+			iter.Skip()
+		}
+		if iter.Error != nil {
+			return d.error()
+		}
+	}
+	d.state = restfulDecodeDone
+	return d.error()
+}
+
+func (d *RestfulDecoder) error() error {
+	if d.iter.Error != nil && d.iter.Error != io.EOF {
+		return d.iter.Error
+	}
+	return nil
+}
+
+// Next decodes the next row of data into dest, io.EOF is returned when there are no more rows.
+func (d *RestfulDecoder) Next(dest []driver.Value) error {
+	if d.state != restfulDecodeData {
+		return io.EOF
+	}
+	iter := d.iter
+	if !iter.ReadArray() {
+		if err := d.error(); err != nil {
+			return err
+		}
+		// the fields after data
+		if err := d.readFields(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	readRow(iter, d.Resp.ColTypes, dest)
+	if iter.Error != nil {
+		if iter.Error == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return iter.Error
+	}
+	return nil
+}
+
+// Done reports whether the whole response has been decoded.
+func (d *RestfulDecoder) Done() bool {
+	return d.state == restfulDecodeDone
+}
+
+// Close releases the iterator, the decoder can not be used after Close.
+func (d *RestfulDecoder) Close() {
+	if d.iter != nil {
+		jsonI.ReturnIterator(d.iter)
+		d.iter = nil
+		d.state = restfulDecodeDone
+	}
+}
+
+func readColumnMeta(iter *jsoniter.Iterator, result *TDEngineRestfulResp) {
+	iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+		index := 0
+		isDecimal := false
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			switch index {
+			case 0:
+				result.ColNames = append(result.ColNames, iter.ReadString())
+				index = 1
+			case 1:
+				typeStr := iter.ReadString()
+				if strings.HasPrefix(typeStr, "DECIMAL(") {
+					// parse DECIMAL(10,2) to DECIMAL, 10, 2
+					precision, scale, err := parseDecimalType(typeStr)
+					if err != nil {
+						iter.ReportError("parse decimal", err.Error())
+						return false
 					}
-					if iter.ReadNil() {
-						row[column] = nil
-						return true
+					isDecimal = true
+					result.Precisions = append(result.Precisions, precision)
+					result.Scales = append(result.Scales, scale)
+				} else {
+					t, exist := NameTypeMap[typeStr]
+					if exist {
+						result.ColTypes = append(result.ColTypes, t)
+					} else {
+						iter.ReportError("unsupported type in column_meta", typeStr)
 					}
-					var err error
-					switch columnType {
-					case TSDB_DATA_TYPE_NULL:
-						iter.Skip()
-						row[column] = nil
-					case TSDB_DATA_TYPE_BOOL:
-						row[column] = iter.ReadAny().ToBool()
-					case TSDB_DATA_TYPE_TINYINT:
-						row[column] = iter.ReadInt8()
-					case TSDB_DATA_TYPE_SMALLINT:
-						row[column] = iter.ReadInt16()
-					case TSDB_DATA_TYPE_INT:
-						row[column] = iter.ReadInt32()
-					case TSDB_DATA_TYPE_BIGINT:
-						row[column] = iter.ReadInt64()
-					case TSDB_DATA_TYPE_FLOAT:
-						row[column] = iter.ReadFloat32()
-					case TSDB_DATA_TYPE_DOUBLE:
-						row[column] = iter.ReadFloat64()
-					case TSDB_DATA_TYPE_BINARY:
-						row[column] = iter.ReadString()
-					case TSDB_DATA_TYPE_TIMESTAMP:
-						b := iter.ReadString()
-						row[column], err = time.Parse(timeFormat, b)
-						if err != nil {
-							iter.ReportError("parse time", err.Error())
-						}
-					case TSDB_DATA_TYPE_NCHAR:
-						row[column] = iter.ReadString()
-					case TSDB_DATA_TYPE_UTINYINT:
-						row[column] = iter.ReadUint8()
-					case TSDB_DATA_TYPE_USMALLINT:
-						row[column] = iter.ReadUint16()
-					case TSDB_DATA_TYPE_UINT:
-						row[column] = iter.ReadUint32()
-					case TSDB_DATA_TYPE_UBIGINT:
-						row[column] = iter.ReadUint64()
-					case TSDB_DATA_TYPE_VARBINARY, TSDB_DATA_TYPE_GEOMETRY:
-						data := iter.ReadStringAsSlice()
-						if len(data)%2 != 0 {
-							iter.ReportError("read varbinary", fmt.Sprintf("invalid length %s", string(data)))
-						}
-						value := make([]byte, len(data)/2)
-						for i := 0; i < len(data); i += 2 {
-							value[i/2] = hexCharToDigit(data[i])<<4 | hexCharToDigit(data[i+1])
-						}
-						row[column] = value
-					case TSDB_DATA_TYPE_DECIMAL, TSDB_DATA_TYPE_DECIMAL64:
-						row[column] = iter.ReadString()
+					result.Precisions = append(result.Precisions, 0)
+					result.Scales = append(result.Scales, 0)
+				}
+				index = 2
+			case 2:
+				colLen := iter.ReadInt64()
+				result.ColLength = append(result.ColLength, colLen)
+				index = 0
+				if isDecimal {
+					switch colLen {
+					case 8:
+						result.ColTypes = append(result.ColTypes, TSDB_DATA_TYPE_DECIMAL64)
+					case 16:
+						result.ColTypes = append(result.ColTypes, TSDB_DATA_TYPE_DECIMAL)
 					default:
-						row[column] = nil
-						iter.Skip()
+						iter.ReportError("parse decimal", fmt.Sprintf("invalid length %d", colLen))
+						return false
 					}
-					return iter.Error == nil
-				})
-				if iter.Error != nil {
-					return false
 				}
-				result.Data = append(result.Data, row)
-				return true
-			})
-		case "rows":
-			result.Rows = iter.ReadInt()
+				isDecimal = false
+			}
+			return true
+		})
+		return true
+	})
+}
+
+func readRow(iter *jsoniter.Iterator, colTypes []int, row []driver.Value) {
+	column := 0
+	iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+		defer func() {
+			column += 1
+		}()
+		if column >= len(colTypes) || column >= len(row) {
+			iter.ReportError("read row", fmt.Sprintf("column count mismatch, expect %d", len(colTypes)))
+			return false
+		}
+		columnType := colTypes[column]
+		if columnType == TSDB_DATA_TYPE_JSON {
+			row[column] = iter.SkipAndReturnBytes()
+			return true
+		}
+		if iter.ReadNil() {
+			row[column] = nil
+			return true
+		}
+		var err error
+		switch columnType {
+		case TSDB_DATA_TYPE_NULL:
+			iter.Skip()
+			row[column] = nil
+		case TSDB_DATA_TYPE_BOOL:
+			row[column] = iter.ReadAny().ToBool()
+		case TSDB_DATA_TYPE_TINYINT:
+			row[column] = iter.ReadInt8()
+		case TSDB_DATA_TYPE_SMALLINT:
+			row[column] = iter.ReadInt16()
+		case TSDB_DATA_TYPE_INT:
+			row[column] = iter.ReadInt32()
+		case TSDB_DATA_TYPE_BIGINT:
+			row[column] = iter.ReadInt64()
+		case TSDB_DATA_TYPE_FLOAT:
+			row[column] = iter.ReadFloat32()
+		case TSDB_DATA_TYPE_DOUBLE:
+			row[column] = iter.ReadFloat64()
+		case TSDB_DATA_TYPE_BINARY:
+			row[column] = iter.ReadString()
+		case TSDB_DATA_TYPE_TIMESTAMP:
+			b := iter.ReadString()
+			row[column], err = time.Parse(time.RFC3339Nano, b)
+			if err != nil {
+				iter.ReportError("parse time", err.Error())
+			}
+		case TSDB_DATA_TYPE_NCHAR:
+			row[column] = iter.ReadString()
+		case TSDB_DATA_TYPE_UTINYINT:
+			row[column] = iter.ReadUint8()
+		case TSDB_DATA_TYPE_USMALLINT:
+			row[column] = iter.ReadUint16()
+		case TSDB_DATA_TYPE_UINT:
+			row[column] = iter.ReadUint32()
+		case TSDB_DATA_TYPE_UBIGINT:
+			row[column] = iter.ReadUint64()
+		case TSDB_DATA_TYPE_VARBINARY, TSDB_DATA_TYPE_GEOMETRY:
+			data := iter.ReadStringAsSlice()
+			if len(data)%2 != 0 {
+				iter.ReportError("read varbinary", fmt.Sprintf("invalid length %s", string(data)))
+			}
+			value := make([]byte, len(data)/2)
+			for i := 0; i < len(data); i += 2 {
+				value[i/2] = hexCharToDigit(data[i])<<4 | hexCharToDigit(data[i+1])
+			}
+			row[column] = value
+		case TSDB_DATA_TYPE_DECIMAL, TSDB_DATA_TYPE_DECIMAL64:
+			row[column] = iter.ReadString()
 		default:
+			row[column] = nil
 			iter.Skip()
 		}
 		return iter.Error == nil
 	})
-	if iter.Error != nil && iter.Error != io.EOF {
-		return nil, iter.Error
-	}
-	return &result, nil
 }
 
 func hexCharToDigit(char byte) uint8 {
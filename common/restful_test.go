@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -349,3 +350,58 @@ func TestUnmarshalRestfulBody(t *testing.T) {
 		})
 	}
 }
+
+func TestRestfulDecoder(t *testing.T) {
+	body := `{"code":0,"column_meta":[["ts","TIMESTAMP",8],["c1","INT",4],["info","JSON",4095]],"data":[["2025-03-20T09:11:11.634Z",1,{"a":1}],["2025-03-20T09:11:12.634Z",null,null]],"rows":2}`
+	ts, err := time.Parse(time.RFC3339Nano, "2025-03-20T09:11:11.634Z")
+	assert.NoError(t, err)
+	// read one byte at a time to make sure rows are decoded from the stream
+	decoder, err := NewRestfulDecoder(iotest.OneByteReader(strings.NewReader(body)), 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer decoder.Close()
+	assert.Equal(t, []string{"ts", "c1", "info"}, decoder.Resp.ColNames)
+	assert.Equal(t, []int{TSDB_DATA_TYPE_TIMESTAMP, TSDB_DATA_TYPE_INT, TSDB_DATA_TYPE_JSON}, decoder.Resp.ColTypes)
+	assert.False(t, decoder.Done())
+	row := make([]driver.Value, 3)
+	err = decoder.Next(row)
+	assert.NoError(t, err)
+	assert.Equal(t, []driver.Value{ts, int32(1), []byte(`{"a":1}`)}, row)
+	err = decoder.Next(row)
+	assert.NoError(t, err)
+	assert.Equal(t, []driver.Value{ts.Add(time.Second), nil, []byte("null")}, row)
+	err = decoder.Next(row)
+	assert.Equal(t, io.EOF, err)
+	assert.True(t, decoder.Done())
+	assert.Equal(t, 2, decoder.Resp.Rows)
+	err = decoder.Next(row)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRestfulDecoderTruncated(t *testing.T) {
+	body := `{"code":0,"column_meta":[["c1","INT",4]],"data":[[1],[2`
+	decoder, err := NewRestfulDecoder(strings.NewReader(body), 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer decoder.Close()
+	row := make([]driver.Value, 1)
+	err = decoder.Next(row)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), row[0])
+	err = decoder.Next(row)
+	assert.Error(t, err)
+	assert.NotEqual(t, io.EOF, err)
+}
+
+func TestRestfulDecoderColumnMismatch(t *testing.T) {
+	body := `{"code":0,"column_meta":[["c1","INT",4]],"data":[[1,2]],"rows":1}`
+	decoder, err := NewRestfulDecoder(strings.NewReader(body), 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer decoder.Close()
+	err = decoder.Next(make([]driver.Value, 1))
+	assert.Error(t, err)
+}
@@ -11,6 +11,12 @@ import (
 )
 
 func InterpolateParams(query string, args []driver.NamedValue) (string, error) {
+	return InterpolateParamsWithLocation(query, args, nil)
+}
+
+// InterpolateParamsWithLocation interpolates the args into query like InterpolateParams,
+// the time.Time args are formatted in loc when loc is not nil.
+func InterpolateParamsWithLocation(query string, args []driver.NamedValue, loc *time.Location) (string, error) {
 	// Number of ? should be same to len(args)
 	if strings.Count(query, "?") != len(args) {
 		return "", driver.ErrSkip
@@ -67,6 +73,9 @@ func InterpolateParams(query string, args []driver.NamedValue) (string, error) {
 			}
 
 		case time.Time:
+			if loc != nil {
+				v = v.In(loc)
+			}
 			t := v.Format(time.RFC3339Nano)
 			buf.WriteByte('\'')
 			buf.WriteString(t)
@@ -101,6 +101,28 @@ func TestInterpolateParams(t *testing.T) {
 	}
 }
 
+func TestInterpolateParamsWithLocation(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []driver.NamedValue{{Ordinal: 1, Value: time.Date(2022, 01, 25, 0, 0, 0, 0, time.UTC)}}
+	got, err := InterpolateParamsWithLocation("select * from t1 where ts = ?", args, shanghai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select * from t1 where ts = '2022-01-25T08:00:00+08:00'"; got != want {
+		t.Errorf("InterpolateParamsWithLocation() got = %v, want %v", got, want)
+	}
+	got, err = InterpolateParamsWithLocation("select * from t1 where ts = ?", args, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select * from t1 where ts = '2022-01-25T00:00:00Z'"; got != want {
+		t.Errorf("InterpolateParamsWithLocation() got = %v, want %v", got, want)
+	}
+}
+
 func TestValueArgsToNamedValueArgs(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,89 @@
+package stmt
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/taosdata/driver-go/v3/types"
+)
+
+// ToStmt2Value converts the types.TaosXxx value into the go type accepted by stmt2 bind,
+// other values are returned unchanged.
+func ToStmt2Value(v driver.Value) driver.Value {
+	switch value := v.(type) {
+	case types.TaosBool:
+		return bool(value)
+	case types.TaosTinyint:
+		return int8(value)
+	case types.TaosSmallint:
+		return int16(value)
+	case types.TaosInt:
+		return int32(value)
+	case types.TaosBigint:
+		return int64(value)
+	case types.TaosUTinyint:
+		return uint8(value)
+	case types.TaosUSmallint:
+		return uint16(value)
+	case types.TaosUInt:
+		return uint32(value)
+	case types.TaosUBigint:
+		return uint64(value)
+	case types.TaosFloat:
+		return float32(value)
+	case types.TaosDouble:
+		return float64(value)
+	case types.TaosBinary:
+		return []byte(value)
+	case types.TaosVarBinary:
+		return []byte(value)
+	case types.TaosNchar:
+		return string(value)
+	case types.TaosTimestamp:
+		return value.T
+	case types.TaosJson:
+		return []byte(value)
+	case types.TaosGeometry:
+		return []byte(value)
+	default:
+		return v
+	}
+}
+
+// RowToStmt2BindData converts a single row of placeholder values into stmt2 bind data.
+// For insert statements the values are distributed to table name, tags and columns by the bind type of fields,
+// for query statements every value is bound as a column.
+func RowToStmt2BindData(isInsert bool, fields []*Stmt2AllField, args []driver.Value) (*TaosStmt2BindData, error) {
+	data := &TaosStmt2BindData{}
+	if !isInsert {
+		data.Cols = make([][]driver.Value, len(args))
+		for i := 0; i < len(args); i++ {
+			data.Cols[i] = []driver.Value{ToStmt2Value(args[i])}
+		}
+		return data, nil
+	}
+	if len(args) != len(fields) {
+		return nil, fmt.Errorf("wrong number of parameters, expect %d, got %d", len(fields), len(args))
+	}
+	for i := 0; i < len(fields); i++ {
+		value := ToStmt2Value(args[i])
+		switch fields[i].BindType {
+		case TAOS_FIELD_TBNAME:
+			switch name := value.(type) {
+			case string:
+				data.TableName = name
+			case []byte:
+				data.TableName = string(name)
+			default:
+				return nil, fmt.Errorf("table name must be string, got %T", value)
+			}
+		case TAOS_FIELD_TAG:
+			data.Tags = append(data.Tags, value)
+		case TAOS_FIELD_COL:
+			data.Cols = append(data.Cols, []driver.Value{value})
+		default:
+			return nil, fmt.Errorf("unsupported field bind type %d", fields[i].BindType)
+		}
+	}
+	return data, nil
+}
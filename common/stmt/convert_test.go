@@ -0,0 +1,79 @@
+package stmt
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/types"
+)
+
+func TestToStmt2Value(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		value driver.Value
+		want  driver.Value
+	}{
+		{name: "bool", value: types.TaosBool(true), want: true},
+		{name: "tinyint", value: types.TaosTinyint(1), want: int8(1)},
+		{name: "smallint", value: types.TaosSmallint(1), want: int16(1)},
+		{name: "int", value: types.TaosInt(1), want: int32(1)},
+		{name: "bigint", value: types.TaosBigint(1), want: int64(1)},
+		{name: "utinyint", value: types.TaosUTinyint(1), want: uint8(1)},
+		{name: "usmallint", value: types.TaosUSmallint(1), want: uint16(1)},
+		{name: "uint", value: types.TaosUInt(1), want: uint32(1)},
+		{name: "ubigint", value: types.TaosUBigint(1), want: uint64(1)},
+		{name: "float", value: types.TaosFloat(1), want: float32(1)},
+		{name: "double", value: types.TaosDouble(1), want: float64(1)},
+		{name: "binary", value: types.TaosBinary("a"), want: []byte("a")},
+		{name: "varbinary", value: types.TaosVarBinary("a"), want: []byte("a")},
+		{name: "nchar", value: types.TaosNchar("a"), want: "a"},
+		{name: "timestamp", value: types.TaosTimestamp{T: now, Precision: common.PrecisionMilliSecond}, want: now},
+		{name: "json", value: types.TaosJson(`{"a":1}`), want: []byte(`{"a":1}`)},
+		{name: "geometry", value: types.TaosGeometry("a"), want: []byte("a")},
+		{name: "nil", value: nil, want: nil},
+		{name: "native", value: int(1), want: int(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ToStmt2Value(tt.value))
+		})
+	}
+}
+
+func TestRowToStmt2BindData(t *testing.T) {
+	now := time.Now()
+	fields := []*Stmt2AllField{
+		{Name: "tbname", FieldType: common.TSDB_DATA_TYPE_BINARY, BindType: TAOS_FIELD_TBNAME},
+		{Name: "t1", FieldType: common.TSDB_DATA_TYPE_INT, BindType: TAOS_FIELD_TAG},
+		{Name: "ts", FieldType: common.TSDB_DATA_TYPE_TIMESTAMP, BindType: TAOS_FIELD_COL},
+		{Name: "c1", FieldType: common.TSDB_DATA_TYPE_NCHAR, BindType: TAOS_FIELD_COL},
+	}
+	data, err := RowToStmt2BindData(true, fields, []driver.Value{
+		types.TaosBinary("tb1"),
+		types.TaosInt(1),
+		types.TaosTimestamp{T: now, Precision: common.PrecisionMilliSecond},
+		types.TaosNchar("a"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &TaosStmt2BindData{
+		TableName: "tb1",
+		Tags:      []driver.Value{int32(1)},
+		Cols:      [][]driver.Value{{now}, {"a"}},
+	}, data)
+
+	_, err = RowToStmt2BindData(true, fields, []driver.Value{types.TaosBinary("tb1")})
+	assert.Error(t, err)
+
+	_, err = RowToStmt2BindData(true, fields[:1], []driver.Value{int32(1)})
+	assert.Error(t, err)
+
+	data, err = RowToStmt2BindData(false, nil, []driver.Value{types.TaosBigint(1), types.TaosBinary("a")})
+	assert.NoError(t, err)
+	assert.Equal(t, &TaosStmt2BindData{
+		Cols: [][]driver.Value{{int64(1)}, {[]byte("a")}},
+	}, data)
+}
@@ -0,0 +1,97 @@
+package common
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// TLSConfigTrue uses the default tls.Config.
+	TLSConfigTrue = "true"
+	// TLSConfigFalse disables the custom tls.Config.
+	TLSConfigFalse = "false"
+	// TLSConfigSkipVerify uses a tls.Config skipping the server certificate verification.
+	TLSConfigSkipVerify = "skip-verify"
+)
+
+var (
+	tlsConfigLock     sync.RWMutex
+	tlsConfigRegistry map[string]*tls.Config
+)
+
+// RegisterTLSConfig registers a custom tls.Config with the name, which can be referenced by the DSN param tls=name,
+// the TLS option of the websocket configs and the tmq config ws.tls.
+// The names true, false and skip-verify are reserved.
+//
+//	rootCertPool := x509.NewCertPool()
+//	pem, err := os.ReadFile("/path/ca-cert.pem")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if ok := rootCertPool.AppendCertsFromPEM(pem); !ok {
+//		log.Fatal("Failed to append PEM.")
+//	}
+//	cert, err := tls.LoadX509KeyPair("/path/client-cert.pem", "/path/client-key.pem")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	common.RegisterTLSConfig("custom", &tls.Config{
+//		RootCAs:      rootCertPool,
+//		Certificates: []tls.Certificate{cert},
+//		ServerName:   "adapter.example.com",
+//		MinVersion:   tls.VersionTLS12,
+//	})
+//	db, err := sql.Open("taosWS", "root:taosdata@wss(adapter.example.com:6041)/?tls=custom")
+func RegisterTLSConfig(name string, config *tls.Config) error {
+	if isReservedTLSConfigName(name) {
+		return fmt.Errorf("tls config name %s is reserved", name)
+	}
+	if config == nil {
+		return fmt.Errorf("tls config %s is nil", name)
+	}
+	tlsConfigLock.Lock()
+	defer tlsConfigLock.Unlock()
+	if tlsConfigRegistry == nil {
+		tlsConfigRegistry = make(map[string]*tls.Config)
+	}
+	tlsConfigRegistry[name] = config
+	return nil
+}
+
+// DeregisterTLSConfig removes the tls.Config registered with the name.
+func DeregisterTLSConfig(name string) {
+	tlsConfigLock.Lock()
+	defer tlsConfigLock.Unlock()
+	delete(tlsConfigRegistry, name)
+}
+
+// GetTLSConfig returns a copy of the tls.Config registered with the name,
+// nil is returned for the empty name and false.
+func GetTLSConfig(name string) (*tls.Config, error) {
+	switch strings.ToLower(name) {
+	case "", TLSConfigFalse:
+		return nil, nil
+	case TLSConfigTrue:
+		return &tls.Config{}, nil
+	case TLSConfigSkipVerify:
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	tlsConfigLock.RLock()
+	config, ok := tlsConfigRegistry[name]
+	tlsConfigLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("tls config %s is not registered", name)
+	}
+	return config.Clone(), nil
+}
+
+func isReservedTLSConfigName(name string) bool {
+	switch strings.ToLower(name) {
+	case "", TLSConfigTrue, TLSConfigFalse, TLSConfigSkipVerify:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLSConfig(t *testing.T) {
+	err := RegisterTLSConfig("true", &tls.Config{})
+	assert.Error(t, err)
+	err = RegisterTLSConfig("Skip-Verify", &tls.Config{})
+	assert.Error(t, err)
+	err = RegisterTLSConfig("custom", nil)
+	assert.Error(t, err)
+
+	config := &tls.Config{ServerName: "adapter.example.com", MinVersion: tls.VersionTLS12}
+	err = RegisterTLSConfig("custom", config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	got, err := GetTLSConfig("custom")
+	assert.NoError(t, err)
+	assert.Equal(t, "adapter.example.com", got.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), got.MinVersion)
+	// the registered config is not affected by the change of the copy
+	got.ServerName = "other"
+	assert.Equal(t, "adapter.example.com", config.ServerName)
+
+	got, err = GetTLSConfig("")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+	got, err = GetTLSConfig("false")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+	got, err = GetTLSConfig("true")
+	assert.NoError(t, err)
+	assert.NotNil(t, got)
+	got, err = GetTLSConfig("skip-verify")
+	assert.NoError(t, err)
+	assert.True(t, got.InsecureSkipVerify)
+
+	DeregisterTLSConfig("custom")
+	_, err = GetTLSConfig("custom")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package tmq
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Consumer is the TMQ consumer implemented by both af/tmq and ws/tmq, so the application code
+// does not depend on the transport.
+type Consumer interface {
+	Subscribe(topic string, rebalanceCb RebalanceCb) error
+	SubscribeTopics(topics []string, rebalanceCb RebalanceCb) error
+	Unsubscribe() error
+	Poll(timeoutMs int) Event
+	Commit() ([]TopicPartition, error)
+	CommitOffsets(offsets []TopicPartition) ([]TopicPartition, error)
+	Seek(partition TopicPartition, ignoredTimeoutMs int) error
+	Assignment() (partitions []TopicPartition, err error)
+	Committed(partitions []TopicPartition, timeoutMs int) (offsets []TopicPartition, err error)
+	Position(partitions []TopicPartition) (offsets []TopicPartition, err error)
+	Close() error
+}
+
+// RebalanceCb is the rebalance callback of Consumer
+type RebalanceCb func(Consumer, Event) error
+
+const (
+	// ConnectTypeKey is the config key choosing the transport of NewConsumer
+	ConnectTypeKey = "td.connect.type"
+	// ConnectTypeNative is the transport of af/tmq
+	ConnectTypeNative = "native"
+	// ConnectTypeWebSocket is the transport of ws/tmq
+	ConnectTypeWebSocket = "ws"
+)
+
+// NewConsumerFunc creates a Consumer of a transport
+type NewConsumerFunc func(conf *ConfigMap) (Consumer, error)
+
+var (
+	consumersLock sync.RWMutex
+	consumers     = map[string]NewConsumerFunc{}
+)
+
+// RegisterConsumer registers the constructor of a transport, it is called by af/tmq and ws/tmq when imported.
+func RegisterConsumer(connectType string, newConsumer NewConsumerFunc) {
+	consumersLock.Lock()
+	defer consumersLock.Unlock()
+	consumers[connectType] = newConsumer
+}
+
+// NewConsumer creates a Consumer of the transport chosen by td.connect.type, which is "native" or "ws".
+// When td.connect.type is not set, ws is chosen if ws.url is set, otherwise native.
+// The package of the transport must be imported to register it, such as
+//
+//	import _ "github.com/taosdata/driver-go/v3/ws/tmq"
+func NewConsumer(conf ConfigMap) (Consumer, error) {
+	connectType, err := conf.Get(ConnectTypeKey, "")
+	if err != nil {
+		return nil, err
+	}
+	if connectType == "" {
+		if _, ok := conf["ws.url"]; ok {
+			connectType = ConnectTypeWebSocket
+		} else {
+			connectType = ConnectTypeNative
+		}
+	}
+	consumersLock.RLock()
+	newConsumer, ok := consumers[connectType.(string)]
+	consumersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported %s: %s, the package of the transport is not imported", ConnectTypeKey, connectType)
+	}
+	confCopy := conf.Clone()
+	delete(confCopy, ConnectTypeKey)
+	return newConsumer(&confCopy)
+}
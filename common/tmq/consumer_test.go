@@ -0,0 +1,70 @@
+package tmq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConsumer struct {
+	Consumer
+	connectType string
+	conf        ConfigMap
+}
+
+func TestNewConsumer(t *testing.T) {
+	for _, connectType := range []string{ConnectTypeNative, ConnectTypeWebSocket} {
+		connectType := connectType
+		RegisterConsumer(connectType, func(conf *ConfigMap) (Consumer, error) {
+			return &mockConsumer{connectType: connectType, conf: *conf}, nil
+		})
+	}
+	tests := []struct {
+		name        string
+		conf        ConfigMap
+		connectType string
+		wantErr     bool
+	}{
+		{
+			name:        "default",
+			conf:        ConfigMap{"group.id": "test"},
+			connectType: ConnectTypeNative,
+		},
+		{
+			name:        "ws url",
+			conf:        ConfigMap{"ws.url": "ws://127.0.0.1:6041"},
+			connectType: ConnectTypeWebSocket,
+		},
+		{
+			name:        "connect type",
+			conf:        ConfigMap{ConnectTypeKey: ConnectTypeNative, "ws.url": "ws://127.0.0.1:6041"},
+			connectType: ConnectTypeNative,
+		},
+		{
+			name:    "unknown connect type",
+			conf:    ConfigMap{ConnectTypeKey: "rest"},
+			wantErr: true,
+		},
+		{
+			name:    "wrong connect type",
+			conf:    ConfigMap{ConnectTypeKey: 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consumer, err := NewConsumer(tt.conf)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			mock := consumer.(*mockConsumer)
+			assert.Equal(t, tt.connectType, mock.connectType)
+			_, exist := mock.conf[ConnectTypeKey]
+			assert.False(t, exist)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package tmq
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TableNameColumn is the column name mapped to the table name of the Data block by Decode
+const TableNameColumn = "tbname"
+
+// Decode Append the rows to dst, which is a pointer to a slice of structs or struct pointers.
+// The struct fields are matched with the column names case-insensitively by the `taos:"name"` tag
+// or by the field name, `taos:"-"` skips the field. The field tagged "tbname" gets the table name
+// when msg.with.table.name is enabled, unless a column is named tbname.
+// NULL sets the zero value or a nil pointer, fields implementing sql.Scanner are scanned,
+// and the decimal values, which are strings, can be decoded into float fields.
+func (d *Data) Decode(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("decode destination must be a non-nil pointer to a slice, got %T", dst)
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Ptr {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("decode destination must be a slice of structs or struct pointers, got %T", dst)
+	}
+	if len(d.Data) != 0 && len(d.Columns) == 0 {
+		return fmt.Errorf("decode data of table %s without column metadata", d.TableName)
+	}
+	fields := map[string][]int{}
+	collectFields(structType, nil, fields)
+	columnFields := make([][]int, len(d.Columns))
+	hasTableNameColumn := false
+	for i, column := range d.Columns {
+		name := strings.ToLower(column.Name)
+		if name == TableNameColumn {
+			hasTableNameColumn = true
+		}
+		columnFields[i] = fields[name]
+	}
+	var tableNameField []int
+	if !hasTableNameColumn && d.TableName != "" {
+		tableNameField = fields[TableNameColumn]
+	}
+	result := slice
+	for rowIndex, row := range d.Data {
+		elem := reflect.New(structType).Elem()
+		for i, value := range row {
+			if i >= len(columnFields) || columnFields[i] == nil {
+				continue
+			}
+			if err := setField(elem.FieldByIndex(columnFields[i]), value); err != nil {
+				return fmt.Errorf("decode row %d column %s: %s", rowIndex, d.Columns[i].Name, err)
+			}
+		}
+		if tableNameField != nil {
+			if err := setField(elem.FieldByIndex(tableNameField), d.TableName); err != nil {
+				return fmt.Errorf("decode row %d column %s: %s", rowIndex, TableNameColumn, err)
+			}
+		}
+		if elemType.Kind() == reflect.Ptr {
+			elem = elem.Addr()
+		}
+		result = reflect.Append(result, elem)
+	}
+	slice.Set(result)
+	return nil
+}
+
+// collectFields maps the lower case column names to the field indexes, the embedded structs are flattened
+// and the outer fields win
+func collectFields(t reflect.Type, index []int, fields map[string][]int) {
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("taos")
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			field.Index = fieldIndex
+			embedded = append(embedded, field)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = field.Name
+		}
+		name = strings.ToLower(name)
+		if _, exist := fields[name]; !exist {
+			fields[name] = fieldIndex
+		}
+	}
+	for _, field := range embedded {
+		collectFields(field.Type, field.Index, fields)
+	}
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+func setField(field reflect.Value, value driver.Value) error {
+	if field.Addr().Type().Implements(scannerType) {
+		return field.Addr().Interface().(sql.Scanner).Scan(value)
+	}
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setField(ptr.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if !field.OverflowInt(v.Int()) {
+				field.SetInt(v.Int())
+				return nil
+			}
+			return fmt.Errorf("value %v overflows %s", value, field.Type())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v.Uint() <= math.MaxInt64 && !field.OverflowInt(int64(v.Uint())) {
+				field.SetInt(int64(v.Uint()))
+				return nil
+			}
+			return fmt.Errorf("value %v overflows %s", value, field.Type())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if !field.OverflowUint(v.Uint()) {
+				field.SetUint(v.Uint())
+				return nil
+			}
+			return fmt.Errorf("value %v overflows %s", value, field.Type())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.Int() >= 0 && !field.OverflowUint(uint64(v.Int())) {
+				field.SetUint(uint64(v.Int()))
+				return nil
+			}
+			return fmt.Errorf("value %v overflows %s", value, field.Type())
+		}
+	case reflect.Float32, reflect.Float64:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			field.SetFloat(v.Float())
+			return nil
+		case reflect.String:
+			f, err := strconv.ParseFloat(v.String(), field.Type().Bits())
+			if err != nil {
+				return err
+			}
+			field.SetFloat(f)
+			return nil
+		}
+	case reflect.String:
+		if b, ok := value.([]byte); ok {
+			field.SetString(string(b))
+			return nil
+		}
+		if v.Kind() == reflect.String {
+			field.SetString(v.String())
+			return nil
+		}
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			switch val := value.(type) {
+			case []byte:
+				field.SetBytes(append([]byte{}, val...))
+				return nil
+			case string:
+				field.SetBytes([]byte(val))
+				return nil
+			}
+		}
+	case reflect.Bool:
+		if v.Kind() == reflect.Bool {
+			field.SetBool(v.Bool())
+			return nil
+		}
+	}
+	return fmt.Errorf("can not assign %T to %s", value, field.Type())
+}
@@ -0,0 +1,143 @@
+package tmq
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/common"
+)
+
+func TestDataDecodeScanner(t *testing.T) {
+	data := &Data{
+		TableName: "ctb0",
+		Columns: []*DataColumn{
+			{Name: "ts", Type: common.TSDB_DATA_TYPE_TIMESTAMP, Bytes: 8},
+			{Name: "c1", Type: common.TSDB_DATA_TYPE_INT, Bytes: 4},
+			{Name: "c2", Type: common.TSDB_DATA_TYPE_FLOAT, Bytes: 4},
+			{Name: "c3", Type: common.TSDB_DATA_TYPE_BINARY, Bytes: 130},
+		},
+		Data: [][]driver.Value{
+			{time.Unix(0, 1700000000000000000), nil, float32(1.5), "a"},
+			{time.Unix(0, 1700000000000000001), int32(1), nil, "b"},
+		},
+	}
+	type row struct {
+		TS      time.Time `taos:"ts"`
+		C1      *int32
+		C2      sql.NullFloat64 `taos:"c2"`
+		Value   string          `taos:"c3"`
+		Table   string          `taos:"tbname"`
+		Ignored string          `taos:"-"`
+	}
+	var rows []row
+	err := data.Decode(&rows)
+	if !assert.NoError(t, err) {
+		return
+	}
+	c1 := int32(1)
+	assert.Equal(t, []row{
+		{
+			TS:    time.Unix(0, 1700000000000000000),
+			C2:    sql.NullFloat64{Float64: 1.5, Valid: true},
+			Value: "a",
+			Table: "ctb0",
+		},
+		{
+			TS:    time.Unix(0, 1700000000000000001),
+			C1:    &c1,
+			Value: "b",
+			Table: "ctb0",
+		},
+	}, rows)
+}
+
+type decodeBase struct {
+	TS time.Time `taos:"ts"`
+}
+
+type decodeRow struct {
+	decodeBase
+	Value   int64   `taos:"v"`
+	Small   *uint8  `taos:"small"`
+	Decimal float64 `taos:"d"`
+	Name    string  `taos:"name"`
+	Bytes   []byte  `taos:"varbin"`
+	Flag    bool
+	Table   string `taos:"tbname"`
+	ignored string
+}
+
+func TestDataDecode(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	data := &Data{
+		TableName: "t1",
+		Columns: []*DataColumn{
+			{Name: "ts", Type: common.TSDB_DATA_TYPE_TIMESTAMP, Bytes: 8},
+			{Name: "v", Type: common.TSDB_DATA_TYPE_INT, Bytes: 4},
+			{Name: "small", Type: common.TSDB_DATA_TYPE_UTINYINT, Bytes: 1},
+			{Name: "d", Type: common.TSDB_DATA_TYPE_DECIMAL64, Bytes: 8, Precision: 10, Scale: 2},
+			{Name: "name", Type: common.TSDB_DATA_TYPE_NCHAR, Bytes: 40},
+			{Name: "varbin", Type: common.TSDB_DATA_TYPE_VARBINARY, Bytes: 10},
+			{Name: "FLAG", Type: common.TSDB_DATA_TYPE_BOOL, Bytes: 1},
+			{Name: "other", Type: common.TSDB_DATA_TYPE_INT, Bytes: 4},
+		},
+		Data: [][]driver.Value{
+			{ts, int32(1), uint8(2), "12.34", "a", []byte{1, 2}, true, int32(3)},
+			{ts.Add(time.Second), nil, nil, nil, nil, nil, nil, nil},
+		},
+	}
+	var rows []*decodeRow
+	err := data.Decode(&rows)
+	if !assert.NoError(t, err) {
+		return
+	}
+	small := uint8(2)
+	assert.Equal(t, []*decodeRow{
+		{
+			decodeBase: decodeBase{TS: ts},
+			Value:      1,
+			Small:      &small,
+			Decimal:    12.34,
+			Name:       "a",
+			Bytes:      []byte{1, 2},
+			Flag:       true,
+			Table:      "t1",
+		},
+		{
+			decodeBase: decodeBase{TS: ts.Add(time.Second)},
+			Table:      "t1",
+		},
+	}, rows)
+
+	// rows are appended
+	err = data.Decode(&rows)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(rows))
+}
+
+func TestDataDecodeError(t *testing.T) {
+	data := &Data{
+		Columns: []*DataColumn{{Name: "v", Type: common.TSDB_DATA_TYPE_BIGINT, Bytes: 8}},
+		Data:    [][]driver.Value{{int64(300)}},
+	}
+	var rows []struct {
+		V int8
+	}
+	assert.Error(t, data.Decode(rows))
+	assert.Error(t, data.Decode(&rows))
+	assert.Equal(t, 0, len(rows))
+	var values []int
+	assert.Error(t, data.Decode(&values))
+	var bools []struct {
+		V bool
+	}
+	assert.Error(t, data.Decode(&bools))
+	noColumns := &Data{Data: [][]driver.Value{{int64(1)}}}
+	var ok []struct {
+		V int64
+	}
+	assert.Error(t, noColumns.Decode(&ok))
+}
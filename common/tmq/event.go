@@ -11,7 +11,22 @@ import (
 type Data struct {
 	TableName string
 	Data      [][]driver.Value
+	// Precision is the timestamp precision of the block
+	Precision int `json:",omitempty"`
+	// Columns is the column metadata of the block, the names are empty when the message carries no schema
+	Columns []*DataColumn `json:",omitempty"`
 }
+
+// DataColumn is the column metadata of a Data block
+type DataColumn struct {
+	Name  string
+	Type  uint8
+	Bytes int64
+	// Precision and Scale are set for the decimal columns
+	Precision uint8
+	Scale     uint8
+}
+
 type Event interface {
 	String() string
 }
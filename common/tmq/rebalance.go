@@ -0,0 +1,213 @@
+package tmq
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRebalanceCheckInterval is the min interval between the assignment checks of Rebalancer
+	DefaultRebalanceCheckInterval = time.Second
+	// RebalanceEventsEnableKey is the config key to return the rebalance events from Poll when there is no rebalance callback,
+	// the value is "true" or "false" and the default is "false", so Poll only returns the messages and the errors.
+	RebalanceEventsEnableKey = "rebalance.events.enable"
+)
+
+// AssignedPartitions is the rebalance event of the partitions (vgroups) newly assigned to the consumer
+type AssignedPartitions struct {
+	Partitions []TopicPartition
+}
+
+func (e AssignedPartitions) String() string {
+	return fmt.Sprintf("AssignedPartitions: %v", e.Partitions)
+}
+
+// RevokedPartitions is the rebalance event of the partitions (vgroups) no longer assigned to the consumer
+type RevokedPartitions struct {
+	Partitions []TopicPartition
+}
+
+func (e RevokedPartitions) String() string {
+	return fmt.Sprintf("RevokedPartitions: %v", e.Partitions)
+}
+
+// Rebalancer detects the assignment changes of a consumer by diffing its assignment, it is shared by af/tmq and ws/tmq.
+// The events are passed to the rebalance callback when it is set, otherwise they are returned by Next
+// if the poll events are enabled, so the consumer returns them from Poll. The assignment is updated
+// only after the callback accepts an event, so a failed event is reported again by the next check.
+type Rebalancer struct {
+	lock       sync.Mutex
+	cb         RebalanceCb
+	pollEvents bool
+	subscribed bool
+	interval   time.Duration
+	lastCheck  time.Time
+	assignment map[partitionKey]TopicPartition
+	pending    []Event
+}
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+func keyOf(p TopicPartition) partitionKey {
+	key := partitionKey{partition: p.Partition}
+	if p.Topic != nil {
+		key.topic = *p.Topic
+	}
+	return key
+}
+
+// NewRebalancer New rebalancer with the rebalance callback, which can be nil
+func NewRebalancer(cb RebalanceCb, pollEvents bool) *Rebalancer {
+	return &Rebalancer{
+		cb:         cb,
+		pollEvents: pollEvents,
+		interval:   DefaultRebalanceCheckInterval,
+		assignment: map[partitionKey]TopicPartition{},
+	}
+}
+
+// Subscribe Replace the rebalance callback and start checking the assignment in Poll, it is called after the consumer subscribes
+func (r *Rebalancer) Subscribe(cb RebalanceCb) {
+	r.lock.Lock()
+	r.cb = cb
+	r.subscribed = true
+	r.lock.Unlock()
+}
+
+// Unsubscribed Stop checking the assignment in Poll and forget it, it is called after the consumer unsubscribes
+func (r *Rebalancer) Unsubscribed() {
+	r.lock.Lock()
+	r.subscribed = false
+	r.lastCheck = time.Time{}
+	r.assignment = map[partitionKey]TopicPartition{}
+	r.lock.Unlock()
+}
+
+// Poll Check the assignment of the subscribed consumer and return the next rebalance event for its Poll,
+// the error of the callback is returned as Error
+func (r *Rebalancer) Poll(c Consumer) Event {
+	r.lock.Lock()
+	subscribed := r.subscribed
+	r.lock.Unlock()
+	if subscribed {
+		if err := r.Check(c); err != nil {
+			return NewTMQErrorWithErr(err)
+		}
+	}
+	return r.Next()
+}
+
+// Check Diff the assignment of the consumer if the check interval has passed, the revoked partitions
+// are reported before the assigned ones. The error of the callback is returned.
+// The assignment is not checked when there is neither the callback nor the poll events.
+func (r *Rebalancer) Check(c Consumer) error {
+	r.lock.Lock()
+	if (r.cb == nil && !r.pollEvents) || time.Since(r.lastCheck) < r.interval {
+		r.lock.Unlock()
+		return nil
+	}
+	r.lastCheck = time.Now()
+	r.lock.Unlock()
+	partitions, err := c.Assignment()
+	if err != nil {
+		// the assignment is not ready, check it at the next poll
+		return nil
+	}
+	current := make(map[partitionKey]TopicPartition, len(partitions))
+	for _, p := range partitions {
+		current[keyOf(p)] = p
+	}
+	var revoked, assigned []TopicPartition
+	r.lock.Lock()
+	for key, p := range r.assignment {
+		if _, ok := current[key]; !ok {
+			revoked = append(revoked, p)
+		}
+	}
+	for key, p := range current {
+		if _, ok := r.assignment[key]; !ok {
+			assigned = append(assigned, p)
+		}
+	}
+	r.lock.Unlock()
+	var events []Event
+	if len(revoked) != 0 {
+		events = append(events, RevokedPartitions{Partitions: revoked})
+	}
+	if len(assigned) != 0 {
+		events = append(events, AssignedPartitions{Partitions: assigned})
+	}
+	return r.dispatch(c, events)
+}
+
+// RevokeAll Report all the assigned partitions as revoked, it is called before the consumer unsubscribes or closes.
+// The assignment is kept when the callback fails.
+func (r *Rebalancer) RevokeAll(c Consumer) error {
+	r.lock.Lock()
+	revoked := make([]TopicPartition, 0, len(r.assignment))
+	for _, p := range r.assignment {
+		revoked = append(revoked, p)
+	}
+	r.lock.Unlock()
+	if len(revoked) == 0 {
+		return nil
+	}
+	return r.dispatch(c, []Event{RevokedPartitions{Partitions: revoked}})
+}
+
+// Next Pop the next rebalance event not passed to the callback, nil if there is none
+func (r *Rebalancer) Next() Event {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.pending) == 0 {
+		return nil
+	}
+	event := r.pending[0]
+	r.pending = r.pending[1:]
+	return event
+}
+
+// dispatch passes the events to the callback in order and applies each accepted event to the assignment,
+// it stops at the first error of the callback
+func (r *Rebalancer) dispatch(c Consumer, events []Event) error {
+	r.lock.Lock()
+	cb := r.cb
+	if cb == nil {
+		if r.pollEvents {
+			r.pending = append(r.pending, events...)
+		}
+		for _, event := range events {
+			r.apply(event)
+		}
+		r.lock.Unlock()
+		return nil
+	}
+	r.lock.Unlock()
+	for _, event := range events {
+		if err := cb(c, event); err != nil {
+			return err
+		}
+		r.lock.Lock()
+		r.apply(event)
+		r.lock.Unlock()
+	}
+	return nil
+}
+
+// apply updates the assignment by the event, the lock must be held
+func (r *Rebalancer) apply(event Event) {
+	switch e := event.(type) {
+	case RevokedPartitions:
+		for _, p := range e.Partitions {
+			delete(r.assignment, keyOf(p))
+		}
+	case AssignedPartitions:
+		for _, p := range e.Partitions {
+			r.assignment[keyOf(p)] = p
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package tmq
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type assignmentConsumer struct {
+	Consumer
+	partitions []TopicPartition
+}
+
+func (c *assignmentConsumer) Assignment() ([]TopicPartition, error) {
+	return c.partitions, nil
+}
+
+func newPartitions(topic string, vgIDs ...int32) []TopicPartition {
+	partitions := make([]TopicPartition, len(vgIDs))
+	for i, vgID := range vgIDs {
+		partitions[i] = TopicPartition{Topic: &topic, Partition: vgID}
+	}
+	return partitions
+}
+
+func vgIDsOf(partitions []TopicPartition) []int32 {
+	vgIDs := make([]int32, len(partitions))
+	for i, p := range partitions {
+		vgIDs[i] = p.Partition
+	}
+	sort.Slice(vgIDs, func(i, j int) bool { return vgIDs[i] < vgIDs[j] })
+	return vgIDs
+}
+
+func TestRebalancerCallback(t *testing.T) {
+	consumer := &assignmentConsumer{partitions: newPartitions("topic", 2, 3)}
+	var events []Event
+	r := NewRebalancer(func(c Consumer, event Event) error {
+		assert.Equal(t, consumer, c)
+		events = append(events, event)
+		return nil
+	}, false)
+	r.interval = 0
+	err := r.Check(consumer)
+	assert.NoError(t, err)
+	if !assert.Equal(t, 1, len(events)) {
+		return
+	}
+	assert.Equal(t, []int32{2, 3}, vgIDsOf(events[0].(AssignedPartitions).Partitions))
+
+	consumer.partitions = newPartitions("topic", 3, 4)
+	events = nil
+	err = r.Check(consumer)
+	assert.NoError(t, err)
+	if !assert.Equal(t, 2, len(events)) {
+		return
+	}
+	assert.Equal(t, []int32{2}, vgIDsOf(events[0].(RevokedPartitions).Partitions))
+	assert.Equal(t, []int32{4}, vgIDsOf(events[1].(AssignedPartitions).Partitions))
+
+	events = nil
+	err = r.Check(consumer)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(events))
+
+	err = r.RevokeAll(consumer)
+	assert.NoError(t, err)
+	if !assert.Equal(t, 1, len(events)) {
+		return
+	}
+	assert.Equal(t, []int32{3, 4}, vgIDsOf(events[0].(RevokedPartitions).Partitions))
+	assert.Nil(t, r.Next())
+}
+
+func TestRebalancerPollEvents(t *testing.T) {
+	consumer := &assignmentConsumer{partitions: newPartitions("topic", 2)}
+	r := NewRebalancer(nil, true)
+	r.interval = 0
+	err := r.Check(consumer)
+	assert.NoError(t, err)
+	consumer.partitions = newPartitions("topic", 3)
+	err = r.Check(consumer)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{2}, vgIDsOf(r.Next().(AssignedPartitions).Partitions))
+	assert.Equal(t, []int32{2}, vgIDsOf(r.Next().(RevokedPartitions).Partitions))
+	assert.Equal(t, []int32{3}, vgIDsOf(r.Next().(AssignedPartitions).Partitions))
+	assert.Nil(t, r.Next())
+}
+
+func TestRebalancerDisabled(t *testing.T) {
+	consumer := &assignmentConsumer{partitions: newPartitions("topic", 2)}
+	r := NewRebalancer(nil, false)
+	r.interval = 0
+	err := r.Check(consumer)
+	assert.NoError(t, err)
+	assert.Nil(t, r.Next())
+	err = r.RevokeAll(consumer)
+	assert.NoError(t, err)
+	assert.Nil(t, r.Next())
+}
+
+func TestRebalancerInterval(t *testing.T) {
+	consumer := &assignmentConsumer{partitions: newPartitions("topic", 2)}
+	r := NewRebalancer(nil, true)
+	err := r.Check(consumer)
+	assert.NoError(t, err)
+	consumer.partitions = newPartitions("topic", 3)
+	err = r.Check(consumer)
+	assert.NoError(t, err)
+	assert.IsType(t, AssignedPartitions{}, r.Next())
+	assert.Nil(t, r.Next())
+}
+
+func TestRebalancerCallbackError(t *testing.T) {
+	consumer := &assignmentConsumer{partitions: newPartitions("topic", 2)}
+	cbErr := errors.New("callback error")
+	fail := true
+	var events []Event
+	r := NewRebalancer(func(c Consumer, event Event) error {
+		if fail {
+			return cbErr
+		}
+		events = append(events, event)
+		return nil
+	}, false)
+	r.interval = 0
+	assert.Equal(t, cbErr, r.Check(consumer))
+	// the failed event is reported again
+	fail = false
+	assert.NoError(t, r.Check(consumer))
+	if !assert.Equal(t, 1, len(events)) {
+		return
+	}
+	assert.Equal(t, []int32{2}, vgIDsOf(events[0].(AssignedPartitions).Partitions))
+
+	// the assignment is kept when revoking fails
+	fail = true
+	assert.Equal(t, cbErr, r.RevokeAll(consumer))
+	fail = false
+	events = nil
+	assert.NoError(t, r.RevokeAll(consumer))
+	if !assert.Equal(t, 1, len(events)) {
+		return
+	}
+	assert.Equal(t, []int32{2}, vgIDsOf(events[0].(RevokedPartitions).Partitions))
+	events = nil
+	assert.NoError(t, r.RevokeAll(consumer))
+	assert.Equal(t, 0, len(events))
+}
+
+func TestRebalancerPoll(t *testing.T) {
+	consumer := &assignmentConsumer{partitions: newPartitions("topic", 2)}
+	r := NewRebalancer(nil, true)
+	r.interval = 0
+	// not subscribed
+	assert.Nil(t, r.Poll(consumer))
+	r.Subscribe(nil)
+	assert.Equal(t, []int32{2}, vgIDsOf(r.Poll(consumer).(AssignedPartitions).Partitions))
+	assert.Nil(t, r.Poll(consumer))
+	r.Unsubscribed()
+	assert.Nil(t, r.Poll(consumer))
+	assert.NoError(t, r.RevokeAll(consumer))
+	assert.Nil(t, r.Next())
+
+	cbErr := errors.New("callback error")
+	r.Subscribe(func(c Consumer, event Event) error {
+		return cbErr
+	})
+	event := r.Poll(consumer)
+	assert.Equal(t, NewTMQErrorWithErr(cbErr), event)
+}
@@ -0,0 +1,293 @@
+package tmq
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRunnerPollTimeout = time.Millisecond * 500
+	defaultRunnerQueueSize   = 16
+)
+
+// Handler handles the messages consumed by Runner.
+type Handler interface {
+	// OnData handles the data of a DataMessage or a MetaDataMessage
+	OnData(ctx context.Context, message *DataMessage) error
+	// OnMeta handles the meta of a MetaMessage or a MetaDataMessage, it is called before OnData for a MetaDataMessage
+	OnMeta(ctx context.Context, message *MetaMessage) error
+	// OnError is called with the errors of polling, handling and committing. Returning nil skips the error,
+	// a failed message is then committed as handled, returning an error stops Runner.Run with it.
+	// It is called concurrently by the vgroups when the concurrency is more than 1.
+	OnError(err error) error
+}
+
+// RunnerConfig is the configuration of Runner.
+type RunnerConfig struct {
+	// Concurrency is the number of the messages handled concurrently, the messages of a vgroup are always handled in order.
+	// The default is 1.
+	Concurrency int
+	// PollTimeout is the timeout of each poll, the default is 500ms.
+	PollTimeout time.Duration
+	// QueueSize is the number of the messages waiting for each handling goroutine, the default is 16.
+	QueueSize int
+}
+
+// Runner runs the poll loop of a subscribed consumer, passes the messages to the handler and commits the offsets
+// of the handled messages, so the messages are consumed at least once. The consumer should disable enable.auto.commit.
+// When the consumer enables rebalance.events.enable, the handled messages are committed before the revoked vgroups are lost.
+type Runner struct {
+	consumer Consumer
+	handler  Handler
+	config   RunnerConfig
+}
+
+type runnerResult struct {
+	partition TopicPartition
+	err       error
+}
+
+// NewRunner New runner of the subscribed consumer
+func NewRunner(consumer Consumer, handler Handler, config RunnerConfig) *Runner {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.PollTimeout <= 0 {
+		config.PollTimeout = defaultRunnerPollTimeout
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultRunnerQueueSize
+	}
+	return &Runner{consumer: consumer, handler: handler, config: config}
+}
+
+// Run Poll and handle the messages until ctx is done or the handler returns an error from OnError.
+// When ctx is done the handling messages are finished and their offsets are committed, the queued ones
+// are left to the next consumption and nil is returned. The context passed to the handler has the values of ctx
+// and is not canceled with ctx, it is canceled after Run returns. The consumer is not closed.
+func (r *Runner) Run(ctx context.Context) error {
+	// the handling messages keep their context until they are finished, stopCtx drops the queued ones
+	handlerCtx, cancelHandlers := context.WithCancel(valueContext{ctx})
+	defer cancelHandlers()
+	stopCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	queues := make([]chan Event, r.config.Concurrency)
+	results := make(chan *runnerResult, r.config.Concurrency*(r.config.QueueSize+1))
+	wg := sync.WaitGroup{}
+	for i := range queues {
+		queues[i] = make(chan Event, r.config.QueueSize)
+		wg.Add(1)
+		go func(queue chan Event) {
+			defer wg.Done()
+			r.work(handlerCtx, stopCtx, queue, results)
+		}(queues[i])
+	}
+	offsets := map[partitionKey]TopicPartition{}
+	inflight := 0
+	var runErr error
+	// collect the results, wait for all the inflight messages when flush is true
+	collect := func(flush bool) {
+		for inflight > 0 && runErr == nil {
+			var result *runnerResult
+			if flush {
+				select {
+				case result = <-results:
+				case <-ctx.Done():
+					return
+				}
+			} else {
+				select {
+				case result = <-results:
+				default:
+					return
+				}
+			}
+			inflight -= 1
+			if result.err != nil {
+				runErr = result.err
+				return
+			}
+			offsets[keyOf(result.partition)] = result.partition
+		}
+	}
+	// commit the handled messages, they are committed even if the runner is stopped by another message
+	commit := func() {
+		if len(offsets) == 0 {
+			return
+		}
+		partitions := make([]TopicPartition, 0, len(offsets))
+		for _, p := range offsets {
+			partitions = append(partitions, p)
+		}
+		offsets = map[partitionKey]TopicPartition{}
+		if _, err := r.consumer.CommitOffsets(partitions); err != nil {
+			if err = r.handler.OnError(err); err != nil && runErr == nil {
+				runErr = err
+			}
+		}
+	}
+	pollTimeout := int(r.config.PollTimeout / time.Millisecond)
+loop:
+	for runErr == nil {
+		collect(false)
+		commit()
+		if runErr != nil || ctx.Err() != nil {
+			break
+		}
+		event := r.consumer.Poll(pollTimeout)
+		var partition TopicPartition
+		switch e := event.(type) {
+		case nil:
+			continue
+		case *DataMessage:
+			partition = e.TopicPartition
+		case *MetaMessage:
+			partition = e.TopicPartition
+		case *MetaDataMessage:
+			partition = e.TopicPartition
+		case RevokedPartitions:
+			// the vgroups are lost, commit all the handled messages before polling again
+			collect(true)
+			commit()
+			continue
+		case Error:
+			runErr = r.handler.OnError(e)
+			continue
+		default:
+			continue
+		}
+		queue := queues[r.route(partition)]
+		for {
+			select {
+			case queue <- event:
+				inflight += 1
+				continue loop
+			case result := <-results:
+				inflight -= 1
+				if result.err != nil {
+					runErr = result.err
+					continue loop
+				}
+				offsets[keyOf(result.partition)] = result.partition
+			case <-ctx.Done():
+				break loop
+			}
+		}
+	}
+	stop()
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+	close(results)
+	for result := range results {
+		if result.err != nil {
+			if runErr == nil {
+				runErr = result.err
+			}
+			continue
+		}
+		offsets[keyOf(result.partition)] = result.partition
+	}
+	commit()
+	return runErr
+}
+
+// route returns the queue index of the vgroup
+func (r *Runner) route(partition TopicPartition) int {
+	h := fnv.New32a()
+	if partition.Topic != nil {
+		_, _ = h.Write([]byte(*partition.Topic))
+	}
+	return int((h.Sum32() + uint32(partition.Partition)) % uint32(r.config.Concurrency))
+}
+
+// work handles the messages of the queue in order with ctx, the queued messages are dropped after stopCtx is done
+// or a handling error stops the runner, so their offsets are not committed.
+func (r *Runner) work(ctx context.Context, stopCtx context.Context, queue chan Event, results chan *runnerResult) {
+	stopped := false
+	for event := range queue {
+		if stopped || stopCtx.Err() != nil {
+			continue
+		}
+		partition, err := r.handle(ctx, event)
+		if err != nil {
+			if err = r.handler.OnError(err); err != nil {
+				stopped = true
+			}
+		}
+		results <- &runnerResult{partition: partition, err: err}
+	}
+}
+
+func (r *Runner) handle(ctx context.Context, event Event) (TopicPartition, error) {
+	switch e := event.(type) {
+	case *DataMessage:
+		return e.TopicPartition, r.handler.OnData(ctx, e)
+	case *MetaMessage:
+		return e.TopicPartition, r.handler.OnMeta(ctx, e)
+	case *MetaDataMessage:
+		meta := &MetaMessage{
+			TopicPartition: e.TopicPartition,
+			dbName:         e.dbName,
+			topic:          e.topic,
+			offset:         e.offset,
+		}
+		data := &DataMessage{
+			TopicPartition: e.TopicPartition,
+			dbName:         e.dbName,
+			topic:          e.topic,
+			offset:         e.offset,
+		}
+		if e.metaData != nil {
+			meta.meta = e.metaData.Meta
+			data.data = e.metaData.Data
+		}
+		if err := r.handler.OnMeta(ctx, meta); err != nil {
+			return e.TopicPartition, err
+		}
+		return e.TopicPartition, r.handler.OnData(ctx, data)
+	}
+	return TopicPartition{}, nil
+}
+
+// valueContext keeps the values of the parent context without its cancellation
+type valueContext struct {
+	context.Context
+}
+
+func (valueContext) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (valueContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (valueContext) Err() error {
+	return nil
+}
+
+// Events Poll the consumer in a goroutine and send the messages, the errors and the rebalance events to the channel,
+// the channel is closed after ctx is done. The offsets are not committed, enable enable.auto.commit
+// or commit the messages by the consumer.
+func Events(ctx context.Context, consumer Consumer, pollTimeoutMs int) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		for ctx.Err() == nil {
+			event := consumer.Poll(pollTimeoutMs)
+			if event == nil {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
@@ -0,0 +1,254 @@
+package tmq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type runnerConsumer struct {
+	Consumer
+	lock      sync.Mutex
+	events    []Event
+	committed map[int32]Offset
+}
+
+func (c *runnerConsumer) Poll(timeoutMs int) Event {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.events) == 0 {
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+	event := c.events[0]
+	c.events = c.events[1:]
+	return event
+}
+
+func (c *runnerConsumer) CommitOffsets(offsets []TopicPartition) ([]TopicPartition, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, p := range offsets {
+		c.committed[p.Partition] = p.Offset
+	}
+	return offsets, nil
+}
+
+func (c *runnerConsumer) getCommitted() map[int32]Offset {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	committed := make(map[int32]Offset, len(c.committed))
+	for k, v := range c.committed {
+		committed[k] = v
+	}
+	return committed
+}
+
+func newDataMessage(vgID int32, offset Offset) *DataMessage {
+	topic := "topic"
+	return &DataMessage{
+		TopicPartition: TopicPartition{Topic: &topic, Partition: vgID, Offset: offset},
+		topic:          topic,
+		offset:         offset,
+	}
+}
+
+type testHandler struct {
+	lock    sync.Mutex
+	handled map[int32][]Offset
+	metas   int
+	errors  int
+	onData  func(ctx context.Context, message *DataMessage) error
+	onError func(err error) error
+}
+
+func (h *testHandler) OnData(ctx context.Context, message *DataMessage) error {
+	if h.onData != nil {
+		if err := h.onData(ctx, message); err != nil {
+			return err
+		}
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.handled[message.TopicPartition.Partition] = append(h.handled[message.TopicPartition.Partition], message.Offset())
+	return nil
+}
+
+func (h *testHandler) OnMeta(ctx context.Context, message *MetaMessage) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.metas += 1
+	return nil
+}
+
+func (h *testHandler) OnError(err error) error {
+	h.lock.Lock()
+	h.errors += 1
+	h.lock.Unlock()
+	if h.onError != nil {
+		return h.onError(err)
+	}
+	return nil
+}
+
+func TestRunner(t *testing.T) {
+	consumer := &runnerConsumer{committed: map[int32]Offset{}}
+	for i := 0; i < 20; i++ {
+		for vgID := int32(1); vgID <= 4; vgID++ {
+			consumer.events = append(consumer.events, newDataMessage(vgID, Offset(i)))
+		}
+	}
+	topic := "topic"
+	consumer.events = append(consumer.events,
+		&MetaDataMessage{TopicPartition: TopicPartition{Topic: &topic, Partition: 1, Offset: 20}, offset: 20, metaData: &MetaData{}},
+		NewTMQError(1, "poll error"),
+		RevokedPartitions{},
+	)
+	handler := &testHandler{
+		handled: map[int32][]Offset{},
+		onData: func(ctx context.Context, message *DataMessage) error {
+			time.Sleep(time.Microsecond * time.Duration(message.TopicPartition.Partition*100))
+			return nil
+		},
+	}
+	runner := NewRunner(consumer, handler, RunnerConfig{Concurrency: 3, PollTimeout: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runner.Run(ctx)
+	}()
+	for i := 0; i < 1000; i++ {
+		committed := consumer.getCommitted()
+		if committed[1] == 20 && committed[2] == 19 && committed[3] == 19 && committed[4] == 19 {
+			break
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+	cancel()
+	assert.NoError(t, <-done)
+	for vgID := int32(1); vgID <= 4; vgID++ {
+		offsets := handler.handled[vgID]
+		expect := 20
+		if vgID == 1 {
+			expect = 21
+		}
+		if !assert.Equal(t, expect, len(offsets)) {
+			continue
+		}
+		for i, offset := range offsets {
+			assert.Equal(t, Offset(i), offset)
+		}
+	}
+	assert.Equal(t, 1, handler.metas)
+	assert.Equal(t, 1, handler.errors)
+	assert.Equal(t, map[int32]Offset{1: 20, 2: 19, 3: 19, 4: 19}, consumer.getCommitted())
+}
+
+func TestRunnerStop(t *testing.T) {
+	consumer := &runnerConsumer{committed: map[int32]Offset{}}
+	for i := 0; i < 5; i++ {
+		consumer.events = append(consumer.events, newDataMessage(1, Offset(i)))
+	}
+	handleErr := errors.New("handle error")
+	handler := &testHandler{
+		handled: map[int32][]Offset{},
+		onData: func(ctx context.Context, message *DataMessage) error {
+			if message.Offset() == 2 {
+				return handleErr
+			}
+			return nil
+		},
+		onError: func(err error) error {
+			return err
+		},
+	}
+	runner := NewRunner(consumer, handler, RunnerConfig{})
+	err := runner.Run(context.Background())
+	assert.Equal(t, handleErr, err)
+	assert.Equal(t, []Offset{0, 1}, handler.handled[1])
+	assert.Equal(t, map[int32]Offset{1: 1}, consumer.getCommitted())
+}
+
+func TestRunnerSkipError(t *testing.T) {
+	consumer := &runnerConsumer{committed: map[int32]Offset{}}
+	for i := 0; i < 3; i++ {
+		consumer.events = append(consumer.events, newDataMessage(1, Offset(i)))
+	}
+	handler := &testHandler{
+		handled: map[int32][]Offset{},
+		onData: func(ctx context.Context, message *DataMessage) error {
+			if message.Offset() == 2 {
+				return errors.New("handle error")
+			}
+			return nil
+		},
+	}
+	runner := NewRunner(consumer, handler, RunnerConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	err := runner.Run(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Offset{0, 1}, handler.handled[1])
+	assert.Equal(t, 1, handler.errors)
+	assert.Equal(t, map[int32]Offset{1: 2}, consumer.getCommitted())
+}
+
+func TestRunnerCancelWhileHandling(t *testing.T) {
+	consumer := &runnerConsumer{committed: map[int32]Offset{}}
+	for i := 0; i < 3; i++ {
+		consumer.events = append(consumer.events, newDataMessage(1, Offset(i)))
+	}
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	handling := make(chan struct{})
+	var handlerErr error
+	var handlerValue interface{}
+	handler := &testHandler{
+		handled: map[int32][]Offset{},
+		onData: func(handlerCtx context.Context, message *DataMessage) error {
+			if message.Offset() == 1 {
+				close(handling)
+				<-ctx.Done()
+				handlerErr = handlerCtx.Err()
+				handlerValue = handlerCtx.Value(key{})
+				// the usual handler gives up when its context is done
+				return handlerCtx.Err()
+			}
+			return nil
+		},
+	}
+	runner := NewRunner(consumer, handler, RunnerConfig{QueueSize: 2})
+	done := make(chan error, 1)
+	go func() {
+		done <- runner.Run(ctx)
+	}()
+	<-handling
+	cancel()
+	assert.NoError(t, <-done)
+	assert.NoError(t, handlerErr)
+	assert.Equal(t, "value", handlerValue)
+	assert.Equal(t, []Offset{0, 1}, handler.handled[1])
+	assert.Equal(t, 0, handler.errors)
+	assert.Equal(t, map[int32]Offset{1: 1}, consumer.getCommitted())
+}
+
+func TestEvents(t *testing.T) {
+	consumer := &runnerConsumer{committed: map[int32]Offset{}}
+	for i := 0; i < 3; i++ {
+		consumer.events = append(consumer.events, newDataMessage(1, Offset(i)))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	events := Events(ctx, consumer, 10)
+	for i := 0; i < 3; i++ {
+		event := <-events
+		assert.Equal(t, Offset(i), event.(*DataMessage).Offset())
+	}
+	cancel()
+	for range events {
+	}
+	assert.Equal(t, 0, len(consumer.getCommitted()))
+}
@@ -0,0 +1,545 @@
+package tmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/taosdata/driver-go/v3/common"
+)
+
+// The alter types of Meta.AlterType
+const (
+	AlterTypeAddTag            = 1
+	AlterTypeDropTag           = 2
+	AlterTypeRenameTag         = 3
+	AlterTypeSetTagValue       = 4
+	AlterTypeAddColumn         = 5
+	AlterTypeDropColumn        = 6
+	AlterTypeModifyColumnBytes = 7
+	AlterTypeModifyTagBytes    = 8
+	AlterTypeRenameColumn      = 10
+)
+
+// The table types of Meta.TableType
+const (
+	TableTypeSuper  = "super"
+	TableTypeChild  = "child"
+	TableTypeNormal = "normal"
+)
+
+// SchemaChange is a typed schema change of a TMQ meta, which is one of CreateSuperTable, CreateNormalTable,
+// CreateChildTables, AlterAddColumn, AlterDropColumn, AlterModifyColumnLength, AlterRenameColumn,
+// AlterSetTagValue, DropTable and DeleteData.
+type SchemaChange interface {
+	// SQL Return the equivalent statement, the table names are not qualified by the database
+	SQL() string
+}
+
+// CreateSuperTable creates a super table
+type CreateSuperTable struct {
+	TableName string
+	Columns   []*Column
+	Tags      []*Column
+}
+
+// CreateNormalTable creates a normal table
+type CreateNormalTable struct {
+	TableName string
+	Columns   []*Column
+}
+
+// ChildTable is a child table created by CreateChildTables
+type ChildTable struct {
+	TableName string
+	Using     string
+	Tags      []*TagValue
+}
+
+// TagValue is a tag value of a child table, Value is nil for NULL and otherwise has the Go type of the tag type,
+// such as int32 for INT, int64 for TIMESTAMP and string for VARCHAR, NCHAR, VARBINARY, GEOMETRY and JSON
+type TagValue struct {
+	Name  string
+	Type  int
+	Value interface{}
+}
+
+// CreateChildTables creates child tables
+type CreateChildTables struct {
+	Tables []*ChildTable
+}
+
+// AlterAddColumn adds a column, or a tag when Tag is true
+type AlterAddColumn struct {
+	TableName string
+	Super     bool
+	Tag       bool
+	Column    *Column
+}
+
+// AlterDropColumn drops a column, or a tag when Tag is true
+type AlterDropColumn struct {
+	TableName string
+	Super     bool
+	Tag       bool
+	Name      string
+}
+
+// AlterModifyColumnLength changes the length of a variable length column, or a tag when Tag is true
+type AlterModifyColumnLength struct {
+	TableName string
+	Super     bool
+	Tag       bool
+	Column    *Column
+}
+
+// AlterRenameColumn renames a column, or a tag when Tag is true
+type AlterRenameColumn struct {
+	TableName string
+	Super     bool
+	Tag       bool
+	Name      string
+	NewName   string
+}
+
+// AlterSetTagValue sets a tag value of a child table. The meta has no tag type, so Value is nil for NULL,
+// string for the quoted values, otherwise int64, uint64, float64 or bool by the literal.
+type AlterSetTagValue struct {
+	TableName string
+	Name      string
+	Value     interface{}
+}
+
+// DropTable drops tables, or a super table when Super is true
+type DropTable struct {
+	Super      bool
+	TableNames []string
+}
+
+// DeleteData deletes data by the statement of the meta
+type DeleteData struct {
+	Statement string
+}
+
+// SchemaChange Decode the meta into a typed schema change, the column types are validated
+// and the tag values are decoded by their types
+func (m *Meta) SchemaChange() (SchemaChange, error) {
+	switch m.Type {
+	case "create":
+		return m.createChange()
+	case "alter":
+		return m.alterChange()
+	case "drop":
+		names := m.TableNameList
+		if len(names) == 0 && m.TableName != "" {
+			names = []string{m.TableName}
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("drop meta without table name")
+		}
+		return &DropTable{Super: m.TableType == TableTypeSuper, TableNames: names}, nil
+	case "delete":
+		if m.SQL == "" {
+			return nil, fmt.Errorf("delete meta without sql")
+		}
+		return &DeleteData{Statement: m.SQL}, nil
+	}
+	return nil, fmt.Errorf("unsupported meta type: %s", m.Type)
+}
+
+func (m *Meta) createChange() (SchemaChange, error) {
+	switch m.TableType {
+	case TableTypeSuper:
+		if err := checkColumns(m.Columns, false); err != nil {
+			return nil, err
+		}
+		tags := make([]*Column, len(m.Tags))
+		for i, tag := range m.Tags {
+			tags[i] = &Column{Name: tag.Name, Type: tag.Type, Length: tag.Length}
+		}
+		if err := checkColumns(tags, true); err != nil {
+			return nil, err
+		}
+		return &CreateSuperTable{TableName: m.TableName, Columns: m.Columns, Tags: tags}, nil
+	case TableTypeNormal:
+		if err := checkColumns(m.Columns, false); err != nil {
+			return nil, err
+		}
+		return &CreateNormalTable{TableName: m.TableName, Columns: m.Columns}, nil
+	case TableTypeChild:
+		items := m.CreateList
+		if len(items) == 0 {
+			items = []*CreateItem{{TableName: m.TableName, Using: m.Using, TagNum: m.TagNum, Tags: m.Tags}}
+		}
+		exactItems := m.exactCreateItems()
+		change := &CreateChildTables{Tables: make([]*ChildTable, len(items))}
+		for i, item := range items {
+			table := &ChildTable{TableName: item.TableName, Using: item.Using, Tags: make([]*TagValue, len(item.Tags))}
+			for j, tag := range item.Tags {
+				tagValue := tag.Value
+				if i < len(exactItems) && j < len(exactItems[i].Tags) && exactItems[i].Tags[j].Name == tag.Name {
+					tagValue = exactItems[i].Tags[j].Value
+				}
+				value, err := decodeTagValue(tag.Type, tagValue)
+				if err != nil {
+					return nil, fmt.Errorf("table %s tag %s: %s", item.TableName, tag.Name, err)
+				}
+				table.Tags[j] = &TagValue{Name: tag.Name, Type: tag.Type, Value: value}
+			}
+			change.Tables[i] = table
+		}
+		return change, nil
+	}
+	return nil, fmt.Errorf("unsupported table type of create meta: %s", m.TableType)
+}
+
+// exactCreateItems decodes the create items of the raw json meta with json.Number tag values,
+// it returns nil when the meta is not parsed by ParseMeta
+func (m *Meta) exactCreateItems() []*CreateItem {
+	if m.raw == nil {
+		return nil
+	}
+	var exact Meta
+	if err := exactMetaJson.Unmarshal(m.raw, &exact); err != nil {
+		return nil
+	}
+	if len(exact.CreateList) != 0 {
+		return exact.CreateList
+	}
+	return []*CreateItem{{TableName: exact.TableName, Using: exact.Using, TagNum: exact.TagNum, Tags: exact.Tags}}
+}
+
+func (m *Meta) alterChange() (SchemaChange, error) {
+	super := m.TableType == TableTypeSuper
+	switch m.AlterType {
+	case AlterTypeAddColumn, AlterTypeAddTag, AlterTypeModifyColumnBytes, AlterTypeModifyTagBytes:
+		tag := m.AlterType == AlterTypeAddTag || m.AlterType == AlterTypeModifyTagBytes
+		column := &Column{Name: m.ColName, Type: m.ColType, Length: m.ColLength}
+		if err := checkColumn(column, tag); err != nil {
+			return nil, err
+		}
+		if m.AlterType == AlterTypeAddColumn || m.AlterType == AlterTypeAddTag {
+			return &AlterAddColumn{TableName: m.TableName, Super: super, Tag: tag, Column: column}, nil
+		}
+		if !isVarType(column.Type) {
+			return nil, fmt.Errorf("column %s: can not modify the length of %s", column.Name, common.GetTypeName(column.Type))
+		}
+		return &AlterModifyColumnLength{TableName: m.TableName, Super: super, Tag: tag, Column: column}, nil
+	case AlterTypeDropColumn, AlterTypeDropTag:
+		return &AlterDropColumn{TableName: m.TableName, Super: super, Tag: m.AlterType == AlterTypeDropTag, Name: m.ColName}, nil
+	case AlterTypeRenameColumn, AlterTypeRenameTag:
+		return &AlterRenameColumn{
+			TableName: m.TableName,
+			Super:     super,
+			Tag:       m.AlterType == AlterTypeRenameTag,
+			Name:      m.ColName,
+			NewName:   m.ColNewName,
+		}, nil
+	case AlterTypeSetTagValue:
+		change := &AlterSetTagValue{TableName: m.TableName, Name: m.ColName}
+		if !m.ColValueNull {
+			change.Value = decodeLiteral(m.ColValue)
+		}
+		return change, nil
+	}
+	return nil, fmt.Errorf("unsupported alter type: %d", m.AlterType)
+}
+
+func isVarType(colType int) bool {
+	switch colType {
+	case common.TSDB_DATA_TYPE_BINARY, common.TSDB_DATA_TYPE_NCHAR, common.TSDB_DATA_TYPE_VARBINARY, common.TSDB_DATA_TYPE_GEOMETRY:
+		return true
+	}
+	return false
+}
+
+func checkColumns(columns []*Column, tag bool) error {
+	for _, column := range columns {
+		if err := checkColumn(column, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkColumn checks the type can be rendered to DDL, the decimal types are not supported
+// because the meta has no precision and scale
+func checkColumn(column *Column, tag bool) error {
+	switch column.Type {
+	case common.TSDB_DATA_TYPE_BOOL,
+		common.TSDB_DATA_TYPE_TINYINT,
+		common.TSDB_DATA_TYPE_SMALLINT,
+		common.TSDB_DATA_TYPE_INT,
+		common.TSDB_DATA_TYPE_BIGINT,
+		common.TSDB_DATA_TYPE_FLOAT,
+		common.TSDB_DATA_TYPE_DOUBLE,
+		common.TSDB_DATA_TYPE_TIMESTAMP,
+		common.TSDB_DATA_TYPE_UTINYINT,
+		common.TSDB_DATA_TYPE_USMALLINT,
+		common.TSDB_DATA_TYPE_UINT,
+		common.TSDB_DATA_TYPE_UBIGINT:
+		return nil
+	case common.TSDB_DATA_TYPE_BINARY, common.TSDB_DATA_TYPE_NCHAR, common.TSDB_DATA_TYPE_VARBINARY, common.TSDB_DATA_TYPE_GEOMETRY:
+		if column.Length <= 0 {
+			return fmt.Errorf("column %s: invalid length %d of %s", column.Name, column.Length, common.GetTypeName(column.Type))
+		}
+		return nil
+	case common.TSDB_DATA_TYPE_JSON:
+		if tag {
+			return nil
+		}
+	}
+	return fmt.Errorf("column %s: unsupported type %d", column.Name, column.Type)
+}
+
+// decodeTagValue decodes the JSON value of a tag, the numbers are json.Number when they are decoded from the raw meta,
+// otherwise float64, and the variable length values are quoted
+func decodeTagValue(tagType int, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch tagType {
+	case common.TSDB_DATA_TYPE_BINARY, common.TSDB_DATA_TYPE_NCHAR, common.TSDB_DATA_TYPE_VARBINARY,
+		common.TSDB_DATA_TYPE_GEOMETRY, common.TSDB_DATA_TYPE_JSON:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s value %v", common.GetTypeName(tagType), value)
+		}
+		return unquote(s), nil
+	case common.TSDB_DATA_TYPE_BOOL:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case float64:
+			return v != 0, nil
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return nil, err
+			}
+			return f != 0, nil
+		}
+		return nil, fmt.Errorf("invalid BOOL value %v", value)
+	}
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case json.Number:
+		if tagType == common.TSDB_DATA_TYPE_UBIGINT {
+			if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+				return u, nil
+			}
+		}
+		if n, err := v.Int64(); err == nil {
+			return convertInt(tagType, n)
+		}
+		var err error
+		f, err = v.Float64()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid %s value %v", common.GetTypeName(tagType), value)
+	}
+	switch tagType {
+	case common.TSDB_DATA_TYPE_FLOAT:
+		return float32(f), nil
+	case common.TSDB_DATA_TYPE_DOUBLE:
+		return f, nil
+	}
+	if f != math.Trunc(f) {
+		return nil, fmt.Errorf("invalid %s value %v", common.GetTypeName(tagType), value)
+	}
+	// float64 of math.MaxInt64 is 1<<63
+	if f >= math.MaxInt64 {
+		if tagType == common.TSDB_DATA_TYPE_UBIGINT && f < math.MaxUint64 {
+			return uint64(f), nil
+		}
+		return nil, fmt.Errorf("value %v overflows %s", value, common.GetTypeName(tagType))
+	}
+	if f < math.MinInt64 {
+		return nil, fmt.Errorf("value %v overflows %s", value, common.GetTypeName(tagType))
+	}
+	return convertInt(tagType, int64(f))
+}
+
+func convertInt(tagType int, n int64) (interface{}, error) {
+	switch tagType {
+	case common.TSDB_DATA_TYPE_TINYINT:
+		if n >= math.MinInt8 && n <= math.MaxInt8 {
+			return int8(n), nil
+		}
+	case common.TSDB_DATA_TYPE_SMALLINT:
+		if n >= math.MinInt16 && n <= math.MaxInt16 {
+			return int16(n), nil
+		}
+	case common.TSDB_DATA_TYPE_INT:
+		if n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int32(n), nil
+		}
+	case common.TSDB_DATA_TYPE_BIGINT, common.TSDB_DATA_TYPE_TIMESTAMP:
+		return n, nil
+	case common.TSDB_DATA_TYPE_UTINYINT:
+		if n >= 0 && n <= math.MaxUint8 {
+			return uint8(n), nil
+		}
+	case common.TSDB_DATA_TYPE_USMALLINT:
+		if n >= 0 && n <= math.MaxUint16 {
+			return uint16(n), nil
+		}
+	case common.TSDB_DATA_TYPE_UINT:
+		if n >= 0 && n <= math.MaxUint32 {
+			return uint32(n), nil
+		}
+	case common.TSDB_DATA_TYPE_UBIGINT:
+		if n >= 0 {
+			return uint64(n), nil
+		}
+	case common.TSDB_DATA_TYPE_FLOAT:
+		return float32(n), nil
+	case common.TSDB_DATA_TYPE_DOUBLE:
+		return float64(n), nil
+	default:
+		return nil, fmt.Errorf("unsupported tag type %d", tagType)
+	}
+	return nil, fmt.Errorf("value %d overflows %s", n, common.GetTypeName(tagType))
+}
+
+// decodeLiteral decodes the tag value of an alter meta, which is quoted for the variable length types
+func decodeLiteral(literal string) interface{} {
+	if len(literal) >= 2 && literal[0] == '"' && literal[len(literal)-1] == '"' {
+		return unquote(literal)
+	}
+	if n, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return n
+	}
+	if n, err := strconv.ParseUint(literal, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(literal); err == nil {
+		return b
+	}
+	return literal
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func (c *CreateSuperTable) SQL() string {
+	return fmt.Sprintf("CREATE STABLE IF NOT EXISTS %s (%s) TAGS (%s)",
+		escapeName(c.TableName), columnsDefinition(c.Columns), columnsDefinition(c.Tags))
+}
+
+func (c *CreateNormalTable) SQL() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", escapeName(c.TableName), columnsDefinition(c.Columns))
+}
+
+func (c *CreateChildTables) SQL() string {
+	b := &strings.Builder{}
+	b.WriteString("CREATE TABLE")
+	for _, table := range c.Tables {
+		names := make([]string, len(table.Tags))
+		values := make([]string, len(table.Tags))
+		for i, tag := range table.Tags {
+			names[i] = escapeName(tag.Name)
+			if s, ok := tag.Value.(string); ok && tag.Type == common.TSDB_DATA_TYPE_VARBINARY && strings.HasPrefix(s, `\x`) {
+				// the hex literal of VARBINARY is not escaped
+				values[i] = "'" + s + "'"
+			} else {
+				values[i] = formatValue(tag.Value)
+			}
+		}
+		fmt.Fprintf(b, " IF NOT EXISTS %s USING %s (%s) TAGS (%s)",
+			escapeName(table.TableName), escapeName(table.Using), strings.Join(names, ","), strings.Join(values, ","))
+	}
+	return b.String()
+}
+
+func (c *AlterAddColumn) SQL() string {
+	return fmt.Sprintf("ALTER %s %s ADD %s %s", tableKeyword(c.Super), escapeName(c.TableName), columnKeyword(c.Tag), columnDefinition(c.Column))
+}
+
+func (c *AlterDropColumn) SQL() string {
+	return fmt.Sprintf("ALTER %s %s DROP %s %s", tableKeyword(c.Super), escapeName(c.TableName), columnKeyword(c.Tag), escapeName(c.Name))
+}
+
+func (c *AlterModifyColumnLength) SQL() string {
+	return fmt.Sprintf("ALTER %s %s MODIFY %s %s", tableKeyword(c.Super), escapeName(c.TableName), columnKeyword(c.Tag), columnDefinition(c.Column))
+}
+
+func (c *AlterRenameColumn) SQL() string {
+	return fmt.Sprintf("ALTER %s %s RENAME %s %s %s", tableKeyword(c.Super), escapeName(c.TableName), columnKeyword(c.Tag), escapeName(c.Name), escapeName(c.NewName))
+}
+
+func (c *AlterSetTagValue) SQL() string {
+	return fmt.Sprintf("ALTER TABLE %s SET TAG %s=%s", escapeName(c.TableName), escapeName(c.Name), formatValue(c.Value))
+}
+
+func (c *DropTable) SQL() string {
+	names := make([]string, len(c.TableNames))
+	for i, name := range c.TableNames {
+		names[i] = "IF EXISTS " + escapeName(name)
+	}
+	return fmt.Sprintf("DROP %s %s", tableKeyword(c.Super), strings.Join(names, ","))
+}
+
+func (c *DeleteData) SQL() string {
+	return c.Statement
+}
+
+func tableKeyword(super bool) string {
+	if super {
+		return "STABLE"
+	}
+	return "TABLE"
+}
+
+func columnKeyword(tag bool) string {
+	if tag {
+		return "TAG"
+	}
+	return "COLUMN"
+}
+
+func escapeName(name string) string {
+	return "`" + name + "`"
+}
+
+func columnDefinition(column *Column) string {
+	if isVarType(column.Type) {
+		return fmt.Sprintf("%s %s(%d)", escapeName(column.Name), common.GetTypeName(column.Type), column.Length)
+	}
+	return escapeName(column.Name) + " " + common.GetTypeName(column.Type)
+}
+
+func columnsDefinition(columns []*Column) string {
+	definitions := make([]string, len(columns))
+	for i, column := range columns {
+		definitions[i] = columnDefinition(column)
+	}
+	return strings.Join(definitions, ",")
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
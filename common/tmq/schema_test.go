@@ -0,0 +1,217 @@
+package tmq
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaSchemaChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		meta   string
+		change SchemaChange
+		sql    string
+	}{
+		{
+			name: "create super table",
+			meta: `{"type":"create","tableType":"super","tableName":"st","columns":[{"name":"ts","type":9},{"name":"c1","type":4},{"name":"c2","type":8,"length":16}],"tags":[{"name":"t1","type":4},{"name":"t2","type":10,"length":8}]}`,
+			change: &CreateSuperTable{
+				TableName: "st",
+				Columns:   []*Column{{Name: "ts", Type: 9}, {Name: "c1", Type: 4}, {Name: "c2", Type: 8, Length: 16}},
+				Tags:      []*Column{{Name: "t1", Type: 4}, {Name: "t2", Type: 10, Length: 8}},
+			},
+			sql: "CREATE STABLE IF NOT EXISTS `st` (`ts` TIMESTAMP,`c1` INT,`c2` VARCHAR(16)) TAGS (`t1` INT,`t2` NCHAR(8))",
+		},
+		{
+			name: "create normal table",
+			meta: `{"type":"create","tableType":"normal","tableName":"nt","columns":[{"name":"ts","type":9},{"name":"c1","type":14}],"tags":[]}`,
+			change: &CreateNormalTable{
+				TableName: "nt",
+				Columns:   []*Column{{Name: "ts", Type: 9}, {Name: "c1", Type: 14}},
+			},
+			sql: "CREATE TABLE IF NOT EXISTS `nt` (`ts` TIMESTAMP,`c1` BIGINT UNSIGNED)",
+		},
+		{
+			name: "create child table",
+			meta: `{"type":"create","tableType":"child","tableName":"ct0","using":"st","tagNum":4,"tags":[{"name":"t1","type":4,"value":1000},{"name":"t2","type":10,"value":"\"it's\""},{"name":"t3","type":1,"value":1},{"name":"t4","type":6}],"createList":[]}`,
+			change: &CreateChildTables{Tables: []*ChildTable{{
+				TableName: "ct0",
+				Using:     "st",
+				Tags: []*TagValue{
+					{Name: "t1", Type: 4, Value: int32(1000)},
+					{Name: "t2", Type: 10, Value: "it's"},
+					{Name: "t3", Type: 1, Value: true},
+					{Name: "t4", Type: 6},
+				},
+			}}},
+			sql: "CREATE TABLE IF NOT EXISTS `ct0` USING `st` (`t1`,`t2`,`t3`,`t4`) TAGS (1000,'it\\'s',true,NULL)",
+		},
+		{
+			name: "create child tables",
+			meta: `{"type":"create","tableType":"child","tableName":"ct1","using":"st","tagNum":1,"tags":[{"name":"t1","type":5,"value":1}],"createList":[{"tableName":"ct1","using":"st","tagNum":1,"tags":[{"name":"t1","type":5,"value":1}]},{"tableName":"ct2","using":"st","tagNum":1,"tags":[{"name":"t1","type":16,"value":"\"\\x0102\""}]}]}`,
+			change: &CreateChildTables{Tables: []*ChildTable{
+				{TableName: "ct1", Using: "st", Tags: []*TagValue{{Name: "t1", Type: 5, Value: int64(1)}}},
+				{TableName: "ct2", Using: "st", Tags: []*TagValue{{Name: "t1", Type: 16, Value: "\\x0102"}}},
+			}},
+			sql: "CREATE TABLE IF NOT EXISTS `ct1` USING `st` (`t1`) TAGS (1) IF NOT EXISTS `ct2` USING `st` (`t1`) TAGS ('\\x0102')",
+		},
+		{
+			name: "create child table with 64-bit tags",
+			meta: `{"type":"create","tableType":"child","tableName":"ct3","using":"st","tagNum":3,"tags":[{"name":"t1","type":5,"value":9223372036854775807},{"name":"t2","type":14,"value":18446744073709551615},{"name":"t3","type":9,"value":1700000000123456789}]}`,
+			change: &CreateChildTables{Tables: []*ChildTable{{
+				TableName: "ct3",
+				Using:     "st",
+				Tags: []*TagValue{
+					{Name: "t1", Type: 5, Value: int64(math.MaxInt64)},
+					{Name: "t2", Type: 14, Value: uint64(math.MaxUint64)},
+					{Name: "t3", Type: 9, Value: int64(1700000000123456789)},
+				},
+			}}},
+			sql: "CREATE TABLE IF NOT EXISTS `ct3` USING `st` (`t1`,`t2`,`t3`) TAGS (9223372036854775807,18446744073709551615,1700000000123456789)",
+		},
+		{
+			name:   "add column",
+			meta:   `{"type":"alter","tableType":"normal","tableName":"nt","alterType":5,"colName":"c2","colType":10,"colLength":20}`,
+			change: &AlterAddColumn{TableName: "nt", Column: &Column{Name: "c2", Type: 10, Length: 20}},
+			sql:    "ALTER TABLE `nt` ADD COLUMN `c2` NCHAR(20)",
+		},
+		{
+			name:   "add tag",
+			meta:   `{"type":"alter","tableType":"super","tableName":"st","alterType":1,"colName":"t3","colType":2}`,
+			change: &AlterAddColumn{TableName: "st", Super: true, Tag: true, Column: &Column{Name: "t3", Type: 2}},
+			sql:    "ALTER STABLE `st` ADD TAG `t3` TINYINT",
+		},
+		{
+			name:   "drop column",
+			meta:   `{"type":"alter","tableType":"super","tableName":"st","alterType":6,"colName":"c1"}`,
+			change: &AlterDropColumn{TableName: "st", Super: true, Name: "c1"},
+			sql:    "ALTER STABLE `st` DROP COLUMN `c1`",
+		},
+		{
+			name:   "modify column length",
+			meta:   `{"type":"alter","tableType":"normal","tableName":"nt","alterType":7,"colName":"c2","colType":8,"colLength":32}`,
+			change: &AlterModifyColumnLength{TableName: "nt", Column: &Column{Name: "c2", Type: 8, Length: 32}},
+			sql:    "ALTER TABLE `nt` MODIFY COLUMN `c2` VARCHAR(32)",
+		},
+		{
+			name:   "rename column",
+			meta:   `{"type":"alter","tableType":"normal","tableName":"nt","alterType":10,"colName":"c1","colNewName":"c3"}`,
+			change: &AlterRenameColumn{TableName: "nt", Name: "c1", NewName: "c3"},
+			sql:    "ALTER TABLE `nt` RENAME COLUMN `c1` `c3`",
+		},
+		{
+			name:   "set tag value",
+			meta:   `{"type":"alter","tableType":"child","tableName":"ct0","alterType":4,"colName":"t2","colValue":"\"abc\"","colValueNull":false}`,
+			change: &AlterSetTagValue{TableName: "ct0", Name: "t2", Value: "abc"},
+			sql:    "ALTER TABLE `ct0` SET TAG `t2`='abc'",
+		},
+		{
+			name:   "set tag number",
+			meta:   `{"type":"alter","tableType":"child","tableName":"ct0","alterType":4,"colName":"t1","colValue":"-5","colValueNull":false}`,
+			change: &AlterSetTagValue{TableName: "ct0", Name: "t1", Value: int64(-5)},
+			sql:    "ALTER TABLE `ct0` SET TAG `t1`=-5",
+		},
+		{
+			name:   "set tag null",
+			meta:   `{"type":"alter","tableType":"child","tableName":"ct0","alterType":4,"colName":"t1","colValueNull":true}`,
+			change: &AlterSetTagValue{TableName: "ct0", Name: "t1"},
+			sql:    "ALTER TABLE `ct0` SET TAG `t1`=NULL",
+		},
+		{
+			name:   "drop tables",
+			meta:   `{"type":"drop","tableNameList":["ct1","ct2"]}`,
+			change: &DropTable{TableNames: []string{"ct1", "ct2"}},
+			sql:    "DROP TABLE IF EXISTS `ct1`,IF EXISTS `ct2`",
+		},
+		{
+			name:   "drop super table",
+			meta:   `{"type":"drop","tableType":"super","tableName":"st"}`,
+			change: &DropTable{Super: true, TableNames: []string{"st"}},
+			sql:    "DROP STABLE IF EXISTS `st`",
+		},
+		{
+			name:   "delete",
+			meta:   `{"type":"delete","sql":"delete from ` + "`ct0`" + ` where ` + "`ts`" + ` >= 1 and ` + "`ts`" + ` <= 2"}`,
+			change: &DeleteData{Statement: "delete from `ct0` where `ts` >= 1 and `ts` <= 2"},
+			sql:    "delete from `ct0` where `ts` >= 1 and `ts` <= 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := ParseMeta([]byte(tt.meta))
+			if !assert.NoError(t, err) {
+				return
+			}
+			change, err := meta.SchemaChange()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.change, change)
+			assert.Equal(t, tt.sql, change.SQL())
+		})
+	}
+}
+
+func TestMetaSchemaChangeError(t *testing.T) {
+	tests := []struct {
+		name string
+		meta string
+	}{
+		{name: "unknown type", meta: `{"type":"unknown"}`},
+		{name: "unknown table type", meta: `{"type":"create","tableType":"view","tableName":"v"}`},
+		{name: "unknown column type", meta: `{"type":"create","tableType":"normal","tableName":"nt","columns":[{"name":"c1","type":99}]}`},
+		{name: "json column", meta: `{"type":"create","tableType":"normal","tableName":"nt","columns":[{"name":"c1","type":15}]}`},
+		{name: "decimal column", meta: `{"type":"create","tableType":"normal","tableName":"nt","columns":[{"name":"c1","type":17}]}`},
+		{name: "missing length", meta: `{"type":"create","tableType":"super","tableName":"st","columns":[{"name":"ts","type":9}],"tags":[{"name":"t1","type":8}]}`},
+		{name: "tag overflow", meta: `{"type":"create","tableType":"child","tableName":"ct0","using":"st","tags":[{"name":"t1","type":2,"value":300}]}`},
+		{name: "tag type mismatch", meta: `{"type":"create","tableType":"child","tableName":"ct0","using":"st","tags":[{"name":"t1","type":4,"value":"\"a\""}]}`},
+		{name: "modify fixed length", meta: `{"type":"alter","tableType":"normal","tableName":"nt","alterType":7,"colName":"c1","colType":4}`},
+		{name: "unknown alter type", meta: `{"type":"alter","tableType":"normal","tableName":"nt","alterType":9}`},
+		{name: "drop without table", meta: `{"type":"drop"}`},
+		{name: "delete without sql", meta: `{"type":"delete"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := ParseMeta([]byte(tt.meta))
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, err = meta.SchemaChange()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecodeTagValueFloat64(t *testing.T) {
+	// the meta decoded without UseNumber has float64 numbers
+	var meta Meta
+	err := json.Unmarshal([]byte(`{"type":"create","tableType":"child","tableName":"ct0","using":"st","tags":[{"name":"t1","type":4,"value":1000},{"name":"t2","type":14,"value":10000000000000000000}]}`), &meta)
+	if !assert.NoError(t, err) {
+		return
+	}
+	change, err := meta.SchemaChange()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []*TagValue{
+		{Name: "t1", Type: 4, Value: int32(1000)},
+		{Name: "t2", Type: 14, Value: uint64(10000000000000000000)},
+	}, change.(*CreateChildTables).Tables[0].Tags)
+}
+
+func TestParseMetaTagValueFloat64(t *testing.T) {
+	meta, err := ParseMeta([]byte(`{"type":"create","tableType":"child","tableName":"ct0","using":"st","tags":[{"name":"t1","type":5,"value":9223372036854775807}]}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	// Tag.Value keeps the float64 of encoding/json
+	assert.IsType(t, float64(0), meta.Tags[0].Value)
+	change, err := meta.SchemaChange()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(math.MaxInt64), change.(*CreateChildTables).Tables[0].Tags[0].Value)
+}
@@ -1,6 +1,30 @@
 package tmq
 
-import "fmt"
+import (
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+var metaJson = jsoniter.ConfigCompatibleWithStandardLibrary
+
+var exactMetaJson = jsoniter.Config{
+	EscapeHTML:             true,
+	SortMapKeys:            true,
+	ValidateJsonRawMessage: true,
+	UseNumber:              true,
+}.Froze()
+
+// ParseMeta Parse the json meta, the numeric tag values are float64 as encoding/json decodes them.
+// The raw json is kept, so SchemaChange decodes the 64-bit integer tag values exactly.
+func ParseMeta(data []byte) (*Meta, error) {
+	var meta Meta
+	if err := metaJson.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	meta.raw = data
+	return &meta, nil
+}
 
 type Meta struct {
 	Type          string        `json:"type"`
@@ -19,12 +43,15 @@ type Meta struct {
 	ColLength     int           `json:"colLength"`
 	ColValue      string        `json:"colValue"`
 	ColValueNull  bool          `json:"colValueNull"`
+	SQL           string        `json:"sql,omitempty"`
+	raw           []byte
 }
 
 type Tag struct {
-	Name  string      `json:"name"`
-	Type  int         `json:"type"`
-	Value interface{} `json:"value"`
+	Name   string      `json:"name"`
+	Type   int         `json:"type"`
+	Length int         `json:"length,omitempty"`
+	Value  interface{} `json:"value"`
 }
 
 type Column struct {
@@ -8,61 +8,75 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 	taosErrors "github.com/taosdata/driver-go/v3/errors"
 )
 
 type taosConn struct {
 	cfg            *Config
 	client         *http.Client
-	url            *url.URL
+	hostLock       sync.RWMutex
+	host           string
 	baseRawQuery   string
-	header         map[string][]string
+	header         http.Header
 	readBufferSize int
+	balancer       *endpoint.Balancer
+	closed         uint32
 }
 
-func newTaosConn(cfg *Config) (*taosConn, error) {
-	readBufferSize := cfg.ReadBufferSize
-	if readBufferSize <= 0 {
-		readBufferSize = 4 << 10
-	}
-	tc := &taosConn{cfg: cfg, readBufferSize: readBufferSize}
+// newHTTPClient creates the http client shared by the connections of a connector,
+// concurrent requests are sent over the pooled keep-alive connections.
+func newHTTPClient(cfg *Config) *http.Client {
 	transport := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
 		DialContext: (&net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: 30 * time.Second,
 		}).DialContext,
+		MaxIdleConnsPerHost:   100,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 		DisableCompression:    cfg.DisableCompression,
 	}
+	if cfg.TLS != nil {
+		transport.TLSClientConfig = cfg.TLS.Clone()
+	}
 	if cfg.SkipVerify {
-		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
 		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
 	}
-	tc.client = &http.Client{
+	return &http.Client{
 		Transport: transport,
 	}
-	path := "/rest/sql"
-	if len(cfg.DbName) != 0 {
-		path = fmt.Sprintf("%s/%s", path, cfg.DbName)
+}
+
+func newTaosConn(cfg *Config, client *http.Client, addr string, balancer *endpoint.Balancer) (*taosConn, error) {
+	readBufferSize := cfg.ReadBufferSize
+	if readBufferSize <= 0 {
+		readBufferSize = 4 << 10
 	}
-	tc.url = &url.URL{
-		Scheme: cfg.Net,
-		Host:   fmt.Sprintf("%s:%d", cfg.Addr, cfg.Port),
-		Path:   path,
+	tc := &taosConn{
+		cfg:            cfg,
+		client:         client,
+		host:           addr,
+		readBufferSize: readBufferSize,
+		balancer:       balancer,
 	}
-	tc.header = map[string][]string{
+	tc.header = http.Header{
 		"Connection": {"keep-alive"},
 	}
 	if cfg.Token != "" {
@@ -82,13 +96,14 @@ func (tc *taosConn) Begin() (driver.Tx, error) {
 }
 
 func (tc *taosConn) Close() (err error) {
-	tc.client = nil
-	tc.url = nil
-	tc.cfg = nil
-	tc.header = nil
+	atomic.StoreUint32(&tc.closed, 1)
 	return nil
 }
 
+func (tc *taosConn) isClosed() bool {
+	return atomic.LoadUint32(&tc.closed) == 1
+}
+
 func (tc *taosConn) Prepare(query string) (driver.Stmt, error) {
 	return nil, &taosErrors.TaosError{Code: 0xffff, ErrStr: "restful does not support stmt"}
 }
@@ -103,7 +118,7 @@ func (tc *taosConn) execCtx(ctx context.Context, query string, args []driver.Nam
 			return nil, driver.ErrSkip
 		}
 		// try to interpolate the parameters to save extra round trips for preparing and closing a statement
-		prepared, err := common.InterpolateParams(query, args)
+		prepared, err := common.InterpolateParamsWithLocation(query, args, tc.cfg.Loc)
 		if err != nil {
 			return nil, err
 		}
@@ -129,93 +144,224 @@ func (tc *taosConn) queryCtx(ctx context.Context, query string, args []driver.Na
 			return nil, driver.ErrSkip
 		}
 		// try client-side prepare to reduce round trip
-		prepared, err := common.InterpolateParams(query, args)
+		prepared, err := common.InterpolateParamsWithLocation(query, args, tc.cfg.Loc)
 		if err != nil {
 			return nil, err
 		}
 		query = prepared
 	}
-	result, err := tc.taosQuery(ctx, query, tc.readBufferSize)
+	opts, err := getRequestOptions(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if result == nil {
-		return nil, errors.New("wrong result")
+	decoder, body, err := tc.query(ctx, query, opts, tc.readBufferSize)
+	if err != nil {
+		return nil, err
 	}
-	// Read Result
+	// the rows are decoded from the body when Next is called
 	rs := &rows{
-		result: result,
+		decoder:  decoder,
+		body:     body,
+		rowLimit: opts.rowLimit,
+		loc:      tc.cfg.Loc,
 	}
-	return rs, err
+	return rs, nil
 }
 
+// Ping checks the server and the credentials with a lightweight query.
+// driver.ErrBadConn is returned when the server can not be reached, so that database/sql drops the connection.
 func (tc *taosConn) Ping(ctx context.Context) (err error) {
-	return nil
+	if tc.isClosed() {
+		return driver.ErrBadConn
+	}
+	_, err = tc.taosQuery(ctx, "select server_version()", 512)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return driver.ErrBadConn
+	}
+	return err
 }
 
 func (tc *taosConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
 	return nil, &taosErrors.TaosError{Code: 0xffff, ErrStr: "restful does not support transaction"}
 }
 
-func (tc *taosConn) taosQuery(ctx context.Context, sql string, bufferSize int) (*common.TDEngineRestfulResp, error) {
-	reqIDValue, err := common.GetReqIDFromCtx(ctx)
-	if err != nil {
-		return nil, err
+// newRequest builds a request owned by the call, nothing shared by the connection is modified,
+// so the connection can be used concurrently.
+func (tc *taosConn) newRequest(ctx context.Context, host string, sql string, opts *requestOptions) (*http.Request, error) {
+	path := "/rest/sql"
+	db := tc.cfg.DbName
+	if opts.db != "" {
+		db = opts.db
 	}
-	if reqIDValue == 0 {
-		reqIDValue = common.GetReqID()
+	if len(db) != 0 {
+		path = fmt.Sprintf("%s/%s", path, db)
 	}
+	rawQuery := fmt.Sprintf("req_id=%d", opts.reqID)
 	if tc.baseRawQuery != "" {
-		tc.url.RawQuery = fmt.Sprintf("%s&req_id=%d", tc.baseRawQuery, reqIDValue)
-	} else {
-		tc.url.RawQuery = fmt.Sprintf("req_id=%d", reqIDValue)
-	}
-	body := ioutil.NopCloser(strings.NewReader(sql))
-	req := &http.Request{
-		Method:     http.MethodPost,
-		URL:        tc.url,
-		Proto:      "HTTP/1.1",
-		ProtoMajor: 1,
-		ProtoMinor: 1,
-		Header:     tc.header,
-		Body:       body,
-		Host:       tc.url.Host,
+		rawQuery = fmt.Sprintf("%s&%s", tc.baseRawQuery, rawQuery)
+	}
+	if opts.timezone != "" {
+		rawQuery = fmt.Sprintf("%s&tz=%s", rawQuery, url.QueryEscape(opts.timezone))
+	}
+	header := make(http.Header, len(tc.header))
+	for k, v := range tc.header {
+		header[k] = v
 	}
+	req, err := http.NewRequest(http.MethodPost, "", strings.NewReader(sql))
+	if err != nil {
+		return nil, err
+	}
+	req.URL = &url.URL{
+		Scheme:   tc.cfg.Net,
+		Host:     host,
+		Path:     path,
+		RawQuery: rawQuery,
+	}
+	req.Host = host
+	req.Header = header
 	if ctx != nil {
 		req = req.WithContext(ctx)
 	}
+	return req, nil
+}
+
+func (tc *taosConn) getHost() string {
+	tc.hostLock.RLock()
+	defer tc.hostLock.RUnlock()
+	return tc.host
+}
+
+func (tc *taosConn) setHost(host string) {
+	tc.hostLock.Lock()
+	tc.host = host
+	tc.hostLock.Unlock()
+}
+
+// doRequest sends the sql, when the endpoint can not be dialed the request has not been sent,
+// so it is safe to try the other endpoints.
+func (tc *taosConn) doRequest(ctx context.Context, sql string, opts *requestOptions) (*http.Response, error) {
+	failed := tc.getHost()
+	req, err := tc.newRequest(ctx, failed, sql, opts)
+	if err != nil {
+		return nil, err
+	}
 	resp, err := tc.client.Do(req)
+	if err == nil || tc.balancer == nil || !isDialError(err) {
+		return resp, err
+	}
+	tc.balancer.MarkFailed(failed)
+	for _, addr := range tc.balancer.Candidates() {
+		if addr == failed {
+			continue
+		}
+		if ctx != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		req, err = tc.newRequest(ctx, addr, sql, opts)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = tc.client.Do(req)
+		if err == nil {
+			tc.balancer.MarkSuccess(addr)
+			tc.setHost(addr)
+			return resp, nil
+		}
+		if !isDialError(err) {
+			return nil, err
+		}
+		tc.balancer.MarkFailed(addr)
+	}
+	return nil, err
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (tc *taosConn) taosQuery(ctx context.Context, sql string, bufferSize int) (*common.TDEngineRestfulResp, error) {
+	opts, err := getRequestOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	decoder, body, err := tc.query(ctx, sql, opts, bufferSize)
 	if err != nil {
 		return nil, err
 	}
 	defer func() {
-		_ = resp.Body.Close()
+		done := decoder.Done()
+		decoder.Close()
+		closeBody(body, done)
 	}()
+	result := decoder.Resp
+	for {
+		row := make([]driver.Value, len(result.ColTypes))
+		err = decoder.Next(row)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Data = append(result.Data, row)
+	}
+}
+
+// query sends the sql and decodes the response until the rows of data, the rows are decoded lazily
+// by the returned decoder. The caller must close the body when the decoder is not used any more.
+func (tc *taosConn) query(ctx context.Context, sql string, opts *requestOptions, bufferSize int) (*common.RestfulDecoder, io.ReadCloser, error) {
+	if tc.isClosed() {
+		return nil, nil, driver.ErrBadConn
+	}
+	resp, err := tc.doRequest(ctx, sql, opts)
+	if err != nil {
+		return nil, nil, err
+	}
 	if resp.StatusCode != http.StatusOK {
+		defer closeBody(resp.Body, false)
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		return nil, fmt.Errorf("server response: %s - %s", resp.Status, string(body))
+		return nil, nil, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
 	}
-	respBody := resp.Body
-	defer func() {
-		_, _ = ioutil.ReadAll(respBody)
-	}()
+	var respBody io.Reader = resp.Body
 	if !tc.cfg.DisableCompression && EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
 		respBody, err = gzip.NewReader(resp.Body)
 		if err != nil {
-			return nil, err
+			closeBody(resp.Body, false)
+			return nil, nil, err
 		}
 	}
-	data, err := common.UnmarshalRestfulBody(respBody, bufferSize)
+	decoder, err := common.NewRestfulDecoder(respBody, bufferSize)
 	if err != nil {
-		return nil, err
+		closeBody(resp.Body, false)
+		return nil, nil, err
+	}
+	if decoder.Resp.Code != 0 {
+		done := decoder.Done()
+		decoder.Close()
+		closeBody(resp.Body, done)
+		return nil, nil, taosErrors.NewError(decoder.Resp.Code, decoder.Resp.Desc)
 	}
-	if data.Code != 0 {
-		return nil, taosErrors.NewError(data.Code, data.Desc)
+	return decoder, resp.Body, nil
+}
+
+// closeBody closes the response body, the remaining of a fully decoded body is drained
+// so that the connection can be reused.
+func closeBody(body io.ReadCloser, drain bool) {
+	if drain {
+		_, _ = io.Copy(ioutil.Discard, body)
 	}
-	return data, nil
+	_ = body.Close()
 }
 
 // EqualFold is strings.EqualFold, ASCII only. It reports whether s and t
@@ -3,17 +3,37 @@ package taosRestful
 import (
 	"context"
 	"database/sql/driver"
+	"fmt"
+	"net/http"
+	"sync"
 
 	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 )
 
 type connector struct {
-	cfg *Config
+	cfg         *Config
+	initOnce    sync.Once
+	client      *http.Client
+	balancer    *endpoint.Balancer
+	balancerErr error
 }
 
 // Connect implements driver.Connector interface.
 // Connect returns a connection to the database.
 func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	// the connections of the connector share the config and the http client
+	c.initOnce.Do(c.init)
+	if c.balancerErr != nil {
+		return nil, c.balancerErr
+	}
+	if c.balancer == nil {
+		return newTaosConn(c.cfg, c.client, fmt.Sprintf("%s:%d", c.cfg.Addr, c.cfg.Port), nil)
+	}
+	return newTaosConn(c.cfg, c.client, c.balancer.Candidates()[0], c.balancer)
+}
+
+func (c *connector) init() {
 	// Connect to Server
 	if len(c.cfg.User) == 0 {
 		c.cfg.User = common.DefaultUser
@@ -24,14 +44,18 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 	if c.cfg.Port == 0 {
 		c.cfg.Port = common.DefaultHttpPort
 	}
-	if len(c.cfg.Net) == 0 {
+	if c.cfg.TLS != nil && (len(c.cfg.Net) == 0 || c.cfg.Net == "http") {
+		c.cfg.Net = "https"
+	} else if len(c.cfg.Net) == 0 {
 		c.cfg.Net = "http"
 	}
 	if len(c.cfg.Addr) == 0 {
 		c.cfg.Addr = "127.0.0.1"
 	}
-	tc, err := newTaosConn(c.cfg)
-	return tc, err
+	c.client = newHTTPClient(c.cfg)
+	if len(c.cfg.Endpoints) != 0 {
+		c.balancer, c.balancerErr = endpoint.NewBalancer(endpoint.FillDefaultPort(c.cfg.Endpoints, common.DefaultHttpPort), c.cfg.EndpointPolicy)
+	}
 }
 
 // Driver implements driver.Connector interface.
@@ -9,20 +9,28 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"math/big"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"net/http/httputil"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/common"
 	"github.com/taosdata/driver-go/v3/types"
 )
 
@@ -569,3 +577,288 @@ func TestConnect(t *testing.T) {
 	driver := conn.Driver()
 	assert.Equal(t, &TDengineDriver{}, driver)
 }
+
+func TestConnectFailover(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"code":0,"column_meta":[["affected_rows","INT",4]],"data":[[1]],"rows":1}`))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// nothing listens on port 1, the dial error fails over to the test server
+	db, err := sql.Open("taosRestful", fmt.Sprintf("root:taosdata@http(127.0.0.1:1,%s)/?endpointPolicy=firstAvailable", u.Host))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	for i := 0; i < 2; i++ {
+		result, err := db.Exec("create database if not exists test_failover")
+		if !assert.NoError(t, err) {
+			return
+		}
+		affected, err := result.RowsAffected()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+	}
+	assert.Equal(t, 2, requests)
+}
+
+func TestCustomTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":0,"column_meta":[["affected_rows","INT",4]],"data":[[1]],"rows":1}`))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	dsn := fmt.Sprintf("root:taosdata@https(%s)/?tls=test_custom_tls", u.Host)
+	_, err = ParseDSN(dsn)
+	assert.Error(t, err)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	err = common.RegisterTLSConfig("test_custom_tls", &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer common.DeregisterTLSConfig("test_custom_tls")
+	db, err := sql.Open("taosRestful", dsn)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	_, err = db.Exec("create database if not exists test_custom_tls")
+	assert.NoError(t, err)
+
+	// the server certificate is not trusted without the custom config
+	db2, err := sql.Open("taosRestful", fmt.Sprintf("root:taosdata@https(%s)/", u.Host))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db2.Close()
+		assert.NoError(t, err)
+	}()
+	_, err = db2.Exec("create database if not exists test_custom_tls")
+	assert.Error(t, err)
+}
+
+func TestStreamingRows(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":0,"column_meta":[["c1","INT",4]],"data":[[1],[2]`))
+		w.(http.Flusher).Flush()
+		// the rest of the result is sent after the first rows are consumed
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(`,[3]],"rows":3}`))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	db, err := sql.Open("taosRestful", fmt.Sprintf("root:taosdata@http(%s)/", u.Host))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	rows, err := db.QueryContext(ctx, "select c1 from t")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var values []int32
+	var v int32
+	for i := 0; i < 2 && rows.Next(); i++ {
+		err = rows.Scan(&v)
+		assert.NoError(t, err)
+		values = append(values, v)
+	}
+	assert.Equal(t, []int32{1, 2}, values)
+	close(release)
+	for rows.Next() {
+		err = rows.Scan(&v)
+		assert.NoError(t, err)
+		values = append(values, v)
+	}
+	assert.NoError(t, rows.Err())
+	assert.Equal(t, []int32{1, 2, 3}, values)
+	assert.NoError(t, rows.Close())
+
+	// close before all rows are read
+	rows, err = db.QueryContext(ctx, "select c1 from t")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, rows.Next())
+	assert.NoError(t, rows.Close())
+}
+
+func TestConcurrentRequestOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// echo the request path and query as the result
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"code":0,"column_meta":[["path","VARCHAR",100],["req_id","VARCHAR",100],["tz","VARCHAR",100]],"data":[[%q,%q,%q],[%q,%q,%q]],"rows":2}`,
+			r.URL.Path, r.URL.Query().Get("req_id"), r.URL.Query().Get("tz"),
+			r.URL.Path, r.URL.Query().Get("req_id"), r.URL.Query().Get("tz"),
+		)))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cfg, err := ParseDSN(fmt.Sprintf("root:taosdata@http(%s)/default_db", u.Host))
+	if !assert.NoError(t, err) {
+		return
+	}
+	c := &connector{cfg: cfg}
+	conn, err := c.Connect(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = conn.Close()
+		assert.NoError(t, err)
+	}()
+	tc := conn.(*taosConn)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := context.WithValue(context.Background(), common.ReqIDKey, int64(i+1))
+			ctx = context.WithValue(ctx, RowLimitKey, 1)
+			wantPath := "/rest/sql/default_db"
+			wantTZ := ""
+			if i%2 == 0 {
+				ctx = context.WithValue(ctx, DBKey, fmt.Sprintf("db_%d", i))
+				ctx = context.WithValue(ctx, TimezoneKey, "Asia/Shanghai")
+				wantPath = fmt.Sprintf("/rest/sql/db_%d", i)
+				wantTZ = "Asia/Shanghai"
+			}
+			rs, err := tc.QueryContext(ctx, "select 1", nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer func() {
+				assert.NoError(t, rs.Close())
+			}()
+			dest := make([]driver.Value, 3)
+			err = rs.Next(dest)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, []driver.Value{wantPath, strconv.Itoa(i + 1), wantTZ}, dest)
+			// limited to 1 row
+			assert.Equal(t, io.EOF, rs.Next(dest))
+		}(i)
+	}
+	wg.Wait()
+
+	ctx := context.WithValue(context.Background(), RowLimitKey, "1")
+	_, err = tc.QueryContext(ctx, "select 1", nil)
+	assert.Error(t, err)
+}
+
+func TestPing(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(http.StatusText(status)))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"column_meta":[["server_version()","VARCHAR",7]],"data":[["3.3.6.0"]],"rows":1}`))
+	}))
+	u, err := url.Parse(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cfg, err := ParseDSN(fmt.Sprintf("root:taosdata@http(%s)/", u.Host))
+	if !assert.NoError(t, err) {
+		return
+	}
+	c := &connector{cfg: cfg}
+	conn, err := c.Connect(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	pinger := conn.(driver.Pinger)
+	assert.NoError(t, pinger.Ping(context.Background()))
+
+	status = http.StatusUnauthorized
+	err = pinger.Ping(context.Background())
+	assert.True(t, errors.Is(err, ErrUnauthorized), err)
+	var httpErr *HTTPError
+	if assert.True(t, errors.As(err, &httpErr)) {
+		assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	}
+	status = http.StatusForbidden
+	err = pinger.Ping(context.Background())
+	assert.True(t, errors.Is(err, ErrForbidden), err)
+	status = http.StatusBadGateway
+	err = pinger.Ping(context.Background())
+	assert.True(t, errors.Is(err, ErrServerError), err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, pinger.Ping(ctx))
+
+	server.Close()
+	assert.Equal(t, driver.ErrBadConn, pinger.Ping(context.Background()))
+	assert.NoError(t, conn.Close())
+	assert.Equal(t, driver.ErrBadConn, pinger.Ping(context.Background()))
+}
+
+func TestLocation(t *testing.T) {
+	var gotSQL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotSQL = string(body)
+		_, _ = w.Write([]byte(`{"code":0,"column_meta":[["ts","TIMESTAMP",8]],"data":[["2022-01-25T00:00:00.000Z"]],"rows":1}`))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	db, err := sql.Open("taosRestful", fmt.Sprintf("root:taosdata@http(%s)/?loc=Asia%%2FShanghai", u.Host))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var ts time.Time
+	err = db.QueryRow("select ts from t where ts = ?", time.Date(2022, 01, 25, 0, 0, 0, 0, time.UTC)).Scan(&ts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "select ts from t where ts = '2022-01-25T08:00:00+08:00'", gotSQL)
+	assert.Equal(t, shanghai, ts.Location())
+	assert.True(t, ts.Equal(time.Date(2022, 01, 25, 0, 0, 0, 0, time.UTC)))
+}
@@ -1,7 +1,9 @@
 package taosRestful
 
 import (
+	"crypto/tls"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -10,6 +12,10 @@ import (
 // @date: 2022/2/8 12:52
 // @description: test parse dsn
 func TestParseDsn(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if !assert.NoError(t, err) {
+		return
+	}
 	tcs := []struct {
 		name string
 		dsn  string
@@ -21,6 +27,33 @@ func TestParseDsn(t *testing.T) {
 			dsn:  "abcd",
 			errs: "invalid DSN: missing the slash separating the database name",
 		},
+		{
+			name: "multiple endpoints",
+			dsn:  "user:passwd@http(a:6041,b:6042)/dbname?endpointPolicy=random",
+			want: &Config{
+				User:               "user",
+				Passwd:             "passwd",
+				Net:                "http",
+				Addr:               "a",
+				Port:               6041,
+				DbName:             "dbname",
+				InterpolateParams:  true,
+				DisableCompression: true,
+				ReadBufferSize:     4096,
+				Endpoints:          []string{"a:6041", "b:6042"},
+				EndpointPolicy:     "random",
+			},
+		},
+		{
+			name: "multiple endpoints wrong port",
+			dsn:  "user:passwd@http(a:6041,b:port)/dbname",
+			errs: "invalid DSN: network port is not a valid number",
+		},
+		{
+			name: "wrong endpoint policy",
+			dsn:  "user:passwd@http(a:6041,b:6042)/dbname?endpointPolicy=wrong",
+			errs: "invalid endpointPolicy value: wrong",
+		},
 		{
 			name: "normal",
 			dsn:  "user:passwd@http(fqdn:6041)/dbname",
@@ -39,6 +72,23 @@ func TestParseDsn(t *testing.T) {
 				SkipVerify:         false,
 			},
 		},
+		{
+			name: "tls upgrades http",
+			dsn:  "user:passwd@http(fqdn:6041)/dbname?tls=skip-verify",
+			want: &Config{
+				User:               "user",
+				Passwd:             "passwd",
+				Net:                "https",
+				Addr:               "fqdn",
+				Port:               6041,
+				DbName:             "dbname",
+				InterpolateParams:  true,
+				DisableCompression: true,
+				ReadBufferSize:     4096,
+				TLSConfig:          "skip-verify",
+				TLS:                &tls.Config{InsecureSkipVerify: true},
+			},
+		},
 		{
 			name: "invalid addr",
 			dsn:  "user:passwd@http()/dbname",
@@ -226,6 +276,25 @@ func TestParseDsn(t *testing.T) {
 				SkipVerify:         false,
 			},
 		},
+		{
+			name: "loc",
+			dsn:  "user:passwd@http(:)/dbname?loc=Asia%2FShanghai",
+			want: &Config{
+				User:               "user",
+				Passwd:             "passwd",
+				Net:                "http",
+				DbName:             "dbname",
+				InterpolateParams:  true,
+				DisableCompression: true,
+				ReadBufferSize:     4096,
+				Loc:                shanghai,
+			},
+		},
+		{
+			name: "wrong loc",
+			dsn:  "user:passwd@http(:)/dbname?loc=wrong",
+			errs: "unknown time zone wrong",
+		},
 		//encodeURIComponent('!q@w#a$1%3^&*()-_+=[]{}:;><?|~,.')
 		{
 			name: "special char2",
@@ -0,0 +1,40 @@
+package taosRestful
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrUnauthorized is matched by errors.Is when the server responds 401, usually caused by wrong credentials.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is matched by errors.Is when the server responds 403.
+	ErrForbidden = errors.New("forbidden")
+	// ErrServerError is matched by errors.Is when the server responds 5xx.
+	ErrServerError = errors.New("server error")
+)
+
+// HTTPError is returned when the server responds with a status other than 200.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("server response: %s - %s", e.Status, e.Body)
+}
+
+func (e *HTTPError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServerError
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package taosRestful
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taosdata/driver-go/v3/common"
+)
+
+// Context keys of the per-request options, set by context.WithValue.
+// The request id is set by common.ReqIDKey.
+const (
+	// TimezoneKey sets the timezone of the timestamps in the result, such as Asia/Shanghai, the value must be a string.
+	TimezoneKey = "taos_timezone"
+	// DBKey overrides the database of the connection for the request, the value must be a string.
+	DBKey = "taos_db"
+	// RowLimitKey limits the number of rows returned by the query, the value must be an int.
+	// The rest of the result is not received once the limit is reached.
+	RowLimitKey = "taos_row_limit"
+)
+
+type requestOptions struct {
+	reqID    int64
+	timezone string
+	db       string
+	rowLimit int
+}
+
+func getRequestOptions(ctx context.Context) (*requestOptions, error) {
+	reqID, err := common.GetReqIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if reqID == 0 {
+		reqID = common.GetReqID()
+	}
+	opts := &requestOptions{reqID: reqID}
+	if v := ctx.Value(TimezoneKey); v != nil {
+		timezone, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s: %v, should be string, got %T", TimezoneKey, v, v)
+		}
+		opts.timezone = timezone
+	}
+	if v := ctx.Value(DBKey); v != nil {
+		db, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s: %v, should be string, got %T", DBKey, v, v)
+		}
+		opts.db = db
+	}
+	if v := ctx.Value(RowLimitKey); v != nil {
+		rowLimit, ok := v.(int)
+		if !ok || rowLimit < 0 {
+			return nil, fmt.Errorf("invalid %s: %v, should be non-negative int, got %T", RowLimitKey, v, v)
+		}
+		opts.rowLimit = rowLimit
+	}
+	return opts, nil
+}
@@ -4,36 +4,40 @@ import (
 	"database/sql/driver"
 	"io"
 	"reflect"
+	"time"
 
 	"github.com/taosdata/driver-go/v3/common"
 )
 
 type rows struct {
-	result   *common.TDEngineRestfulResp
-	rowIndex int
+	decoder  *common.RestfulDecoder
+	body     io.ReadCloser
+	rowLimit int
+	rowCount int
+	loc      *time.Location
 }
 
 func (rs *rows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
-	if rs.result.ColTypes[index] == common.TSDB_DATA_TYPE_DECIMAL || rs.result.ColTypes[index] == common.TSDB_DATA_TYPE_DECIMAL64 {
-		return rs.result.Precisions[index], rs.result.Scales[index], true
+	if rs.decoder.Resp.ColTypes[index] == common.TSDB_DATA_TYPE_DECIMAL || rs.decoder.Resp.ColTypes[index] == common.TSDB_DATA_TYPE_DECIMAL64 {
+		return rs.decoder.Resp.Precisions[index], rs.decoder.Resp.Scales[index], true
 	}
 	return 0, 0, false
 }
 
 func (rs *rows) Columns() []string {
-	return rs.result.ColNames
+	return rs.decoder.Resp.ColNames
 }
 
 func (rs *rows) ColumnTypeDatabaseTypeName(i int) string {
-	return common.GetTypeName(rs.result.ColTypes[i])
+	return common.GetTypeName(rs.decoder.Resp.ColTypes[i])
 }
 
 func (rs *rows) ColumnTypeLength(i int) (length int64, ok bool) {
-	return rs.result.ColLength[i], ok
+	return rs.decoder.Resp.ColLength[i], ok
 }
 
 func (rs *rows) ColumnTypeScanType(i int) reflect.Type {
-	t, exist := common.ColumnTypeMap[rs.result.ColTypes[i]]
+	t, exist := common.ColumnTypeMap[rs.decoder.Resp.ColTypes[i]]
 	if !exist {
 		return common.UnknownType
 	}
@@ -41,14 +45,26 @@ func (rs *rows) ColumnTypeScanType(i int) reflect.Type {
 }
 
 func (rs *rows) Close() error {
+	if rs.body == nil {
+		return nil
+	}
+	// an unfinished body is closed without draining, which stops receiving the rest of the result
+	done := rs.decoder.Done()
+	rs.decoder.Close()
+	closeBody(rs.body, done)
+	rs.body = nil
 	return nil
 }
 
 func (rs *rows) Next(dest []driver.Value) error {
-	if rs.rowIndex >= len(rs.result.Data) {
+	if rs.rowLimit > 0 && rs.rowCount >= rs.rowLimit {
 		return io.EOF
 	}
-	copy(dest, rs.result.Data[rs.rowIndex])
-	rs.rowIndex += 1
+	err := rs.decoder.Next(dest)
+	if err != nil {
+		return err
+	}
+	common.RowTimeIn(dest, rs.loc)
+	rs.rowCount += 1
 	return nil
 }
@@ -8,12 +8,47 @@ import (
 	"github.com/taosdata/driver-go/v3/common"
 	"github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/cgo"
 	"github.com/taosdata/driver-go/v3/wrapper/handler"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
+// Notifier is implemented by the driver connection, it can be used by sql.Conn.Raw
+// to receive the notifications sent by the server:
+//
+//	err = conn.Raw(func(driverConn interface{}) error {
+//		return driverConn.(taosSql.Notifier).SetNotifyHandler(handler)
+//	})
+//
+// The handler is released when the connection is closed by the pool.
+type Notifier interface {
+	// SetNotifyHandler sets the handler of the notifications such as password change, whitelist change and user dropped.
+	// The handler is called in order in a separate goroutine, set nil to drop the notifications.
+	SetNotifyHandler(fn func(common.NotifyEvent)) error
+}
+
+// CgoLocker is implemented by the connections and the connectors of taosSql, it returns the locker limiting
+// the concurrent cgo calls. The connections of a connector share the locker of the connector, which can be
+// resized and observed at runtime.
+//
+//	err = conn.Raw(func(driverConn interface{}) error {
+//		stats := driverConn.(taosSql.CgoLocker).Locker().Stats()
+//		...
+//	})
+type CgoLocker interface {
+	Locker() *thread.Locker
+}
+
 type taosConn struct {
-	taos unsafe.Pointer
-	cfg  *Config
+	taos     unsafe.Pointer
+	cfg      *Config
+	notifier *handler.Notifier
+	locker   *thread.Locker
+}
+
+// Locker returns the locker limiting the cgo calls of the connection
+func (tc *taosConn) Locker() *thread.Locker {
+	return tc.locker
 }
 
 func (tc *taosConn) Begin() (driver.Tx, error) {
@@ -22,11 +57,68 @@ func (tc *taosConn) Begin() (driver.Tx, error) {
 
 func (tc *taosConn) Close() (err error) {
 	if tc.taos != nil {
-		locker.Lock()
+		tc.locker.Lock()
 		wrapper.TaosClose(tc.taos)
-		locker.Unlock()
+		tc.locker.Unlock()
 	}
 	tc.taos = nil
+	if tc.notifier != nil {
+		tc.notifier.Close()
+		tc.notifier = nil
+	}
+	return nil
+}
+
+// setOptions sets the per-connection options of the config.
+func (tc *taosConn) setOptions() error {
+	options := []struct {
+		option int
+		value  string
+	}{
+		{option: common.TSDB_OPTION_CONNECTION_CHARSET, value: tc.cfg.ConnCharset},
+		{option: common.TSDB_OPTION_CONNECTION_TIMEZONE, value: tc.cfg.ConnTimezone},
+		{option: common.TSDB_OPTION_CONNECTION_USER_IP, value: tc.cfg.UserIP},
+		{option: common.TSDB_OPTION_CONNECTION_USER_APP, value: tc.cfg.UserApp},
+	}
+	for _, opt := range options {
+		if opt.value == "" {
+			continue
+		}
+		value := opt.value
+		tc.locker.Lock()
+		code := wrapper.TaosOptionsConnection(tc.taos, opt.option, &value)
+		if code != 0 {
+			errStr := wrapper.TaosErrorStr(nil)
+			tc.locker.Unlock()
+			return errors.NewError(code, errStr)
+		}
+		tc.locker.Unlock()
+	}
+	if tc.cfg.BIMode {
+		tc.locker.Lock()
+		code := wrapper.TaosSetConnMode(tc.taos, common.TAOS_CONN_MODE_BI, 1)
+		if code != 0 {
+			errStr := wrapper.TaosErrorStr(nil)
+			tc.locker.Unlock()
+			return errors.NewError(code, errStr)
+		}
+		tc.locker.Unlock()
+	}
+	return nil
+}
+
+func (tc *taosConn) SetNotifyHandler(fn func(common.NotifyEvent)) error {
+	if tc.taos == nil {
+		return driver.ErrBadConn
+	}
+	if tc.notifier == nil {
+		notifier, err := handler.RegisterNotifier(tc.taos, tc.locker)
+		if err != nil {
+			return err
+		}
+		tc.notifier = notifier
+	}
+	tc.notifier.SetHandler(fn)
 	return nil
 }
 
@@ -34,35 +126,58 @@ func (tc *taosConn) Prepare(query string) (driver.Stmt, error) {
 	if tc.taos == nil {
 		return nil, errors.ErrTscInvalidConnection
 	}
-	locker.Lock()
-	stmtP := wrapper.TaosStmtInit(tc.taos)
-	code := wrapper.TaosStmtPrepare(stmtP, query)
-	locker.Unlock()
-	if err := checkStmtError(code, stmtP); err != nil {
+	handle, caller := handler.GlobalStmt2CallBackCallerPool.Get()
+	tc.locker.Lock()
+	stmtP := wrapper.TaosStmt2Init(tc.taos, common.GetReqID(), false, false, handle)
+	if stmtP == nil {
+		errCode := wrapper.TaosError(nil)
+		errStr := wrapper.TaosErrorStr(nil)
+		tc.locker.Unlock()
+		handler.GlobalStmt2CallBackCallerPool.Put(handle)
+		return nil, errors.NewError(errCode, errStr)
+	}
+	code := wrapper.TaosStmt2Prepare(stmtP, query)
+	tc.locker.Unlock()
+	if err := tc.checkStmtError(code, stmtP, handle); err != nil {
 		return nil, err
 	}
-	locker.Lock()
-	isInsert, code := wrapper.TaosStmtIsInsert(stmtP)
-	locker.Unlock()
-	if err := checkStmtError(code, stmtP); err != nil {
+	tc.locker.Lock()
+	isInsert, code := wrapper.TaosStmt2IsInsert(stmtP)
+	tc.locker.Unlock()
+	if err := tc.checkStmtError(code, stmtP, handle); err != nil {
 		return nil, err
 	}
 	stmt := &Stmt{
 		tc:       tc,
 		pSql:     query,
 		stmt:     stmtP,
+		handle:   handle,
+		caller:   caller,
 		isInsert: isInsert,
 	}
+	if isInsert {
+		tc.locker.Lock()
+		code, count, fieldsP := wrapper.TaosStmt2GetFields(stmtP)
+		if code == 0 {
+			stmt.fields = wrapper.Stmt2ParseAllFields(count, fieldsP)
+			wrapper.TaosStmt2FreeFields(stmtP, fieldsP)
+		}
+		tc.locker.Unlock()
+		if err := tc.checkStmtError(code, stmtP, handle); err != nil {
+			return nil, err
+		}
+	}
 	return stmt, nil
 }
 
-func checkStmtError(code int, stmtP unsafe.Pointer) error {
+func (tc *taosConn) checkStmtError(code int, stmtP unsafe.Pointer, handle cgo.Handle) error {
 	if code != 0 {
-		errStr := wrapper.TaosStmtErrStr(stmtP)
+		errStr := wrapper.TaosStmt2Error(stmtP)
 		err := errors.NewError(code, errStr)
-		locker.Lock()
-		wrapper.TaosStmtClose(stmtP)
-		locker.Unlock()
+		tc.locker.Lock()
+		wrapper.TaosStmt2Close(stmtP)
+		tc.locker.Unlock()
+		handler.GlobalStmt2CallBackCallerPool.Put(handle)
 		return err
 	}
 	return nil
@@ -86,24 +201,27 @@ func (tc *taosConn) execCtx(ctx context.Context, query string, args []driver.Nam
 			return nil, driver.ErrSkip
 		}
 		// try to interpolate the parameters to save extra round trips for preparing and closing a statement
-		prepared, err := common.InterpolateParams(query, args)
+		prepared, err := common.InterpolateParamsWithLocation(query, args, tc.cfg.Loc)
 		if err != nil {
 			return nil, err
 		}
 		query = prepared
 	}
 	h := asyncHandlerPool.Get()
-	defer asyncHandlerPool.Put(h)
-	result := tc.taosQuery(query, h, reqIDValue)
+	result, err := tc.taosQuery(ctx, query, h, reqIDValue)
+	if err != nil {
+		return nil, err
+	}
+	asyncHandlerPool.Put(h)
 	return tc.processExecResult(result)
 }
 
 func (tc *taosConn) processExecResult(result *handler.AsyncResult) (driver.Result, error) {
 	defer func() {
 		if result != nil && result.Res != nil {
-			locker.Lock()
+			tc.locker.Lock()
 			wrapper.TaosFreeResult(result.Res)
-			locker.Unlock()
+			tc.locker.Unlock()
 		}
 	}()
 	res := result.Res
@@ -133,26 +251,29 @@ func (tc *taosConn) queryCtx(ctx context.Context, query string, args []driver.Na
 			return nil, driver.ErrSkip
 		}
 		// try client-side prepare to reduce round trip
-		prepared, err := common.InterpolateParams(query, args)
+		prepared, err := common.InterpolateParamsWithLocation(query, args, tc.cfg.Loc)
 		if err != nil {
 			return nil, err
 		}
 		query = prepared
 	}
 	h := asyncHandlerPool.Get()
-	result := tc.taosQuery(query, h, reqIDValue)
-	return tc.processRows(result, h)
+	result, err := tc.taosQuery(ctx, query, h, reqIDValue)
+	if err != nil {
+		return nil, err
+	}
+	return tc.processRows(ctx, result, h)
 }
 
-func (tc *taosConn) processRows(result *handler.AsyncResult, h *handler.Handler) (driver.Rows, error) {
+func (tc *taosConn) processRows(ctx context.Context, result *handler.AsyncResult, h *handler.Handler) (driver.Rows, error) {
 	res := result.Res
 	code := wrapper.TaosError(res)
 	if code != int(errors.SUCCESS) {
 		asyncHandlerPool.Put(h)
 		errStr := wrapper.TaosErrorStr(res)
-		locker.Lock()
+		tc.locker.Lock()
 		wrapper.TaosFreeResult(result.Res)
-		locker.Unlock()
+		tc.locker.Unlock()
 		return nil, errors.NewError(code, errStr)
 	}
 	numFields := wrapper.TaosNumFields(res)
@@ -163,10 +284,13 @@ func (tc *taosConn) processRows(result *handler.AsyncResult, h *handler.Handler)
 	}
 	precision := wrapper.TaosResultPrecision(res)
 	rs := &rows{
+		ctx:        ctx,
 		handler:    h,
 		rowsHeader: rowsHeader,
 		result:     res,
 		precision:  precision,
+		loc:        tc.cfg.Loc,
+		locker:     tc.locker,
 	}
 	return rs, nil
 }
@@ -179,14 +303,35 @@ func (tc *taosConn) Ping(ctx context.Context) (err error) {
 	return errors.ErrTscInvalidConnection
 }
 
-func (tc *taosConn) taosQuery(sqlStr string, handler *handler.Handler, reqID int64) *handler.AsyncResult {
-	locker.Lock()
+// taosQuery runs the query asynchronously and waits for the result or the context to be done.
+// When the context is done the result is stopped and released once the pending callback arrives,
+// the connection is not killed so the other queries sharing it go on. The caller must not use the handler anymore.
+func (tc *taosConn) taosQuery(ctx context.Context, sqlStr string, handler *handler.Handler, reqID int64) (*handler.AsyncResult, error) {
+	if err := ctx.Err(); err != nil {
+		asyncHandlerPool.Put(handler)
+		return nil, err
+	}
+	tc.locker.Lock()
 	if reqID == 0 {
 		wrapper.TaosQueryA(tc.taos, sqlStr, handler.Handler)
 	} else {
 		wrapper.TaosQueryAWithReqID(tc.taos, sqlStr, handler.Handler, reqID)
 	}
-	locker.Unlock()
-	r := <-handler.Caller.QueryResult
-	return r
+	tc.locker.Unlock()
+	select {
+	case r := <-handler.Caller.QueryResult:
+		return r, nil
+	case <-ctx.Done():
+		go func() {
+			r := <-handler.Caller.QueryResult
+			if r.Res != nil {
+				tc.locker.Lock()
+				wrapper.TaosStopQuery(r.Res)
+				wrapper.TaosFreeResult(r.Res)
+				tc.locker.Unlock()
+			}
+			asyncHandlerPool.Put(handler)
+		}()
+		return nil, ctx.Err()
+	}
 }
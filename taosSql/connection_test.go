@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/taosdata/driver-go/v3/common"
@@ -77,3 +78,110 @@ func TestWrongReqID(t *testing.T) {
 	_, err = db.ExecContext(ctx, "create database if not exists test_wrong_req_id")
 	assert.Error(t, err)
 }
+
+func TestTaosConn_QueryContextCancel(t *testing.T) {
+	db, err := sql.Open("taosSql", dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = conn.Close()
+		assert.NoError(t, err)
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = conn.QueryContext(ctx, "select 1")
+	assert.Equal(t, context.Canceled, err)
+	_, err = conn.ExecContext(ctx, "create database if not exists test_cancel")
+	assert.Equal(t, context.Canceled, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	rs, err := conn.QueryContext(ctx, "select * from information_schema.ins_columns")
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	cancel()
+	for rs.Next() {
+	}
+	assert.Equal(t, context.Canceled, rs.Err())
+	err = rs.Close()
+	assert.NoError(t, err)
+
+	// the connection is still usable after cancellation
+	var v int64
+	err = conn.QueryRowContext(context.Background(), "select 1").Scan(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+}
+
+func TestNotifier(t *testing.T) {
+	db, err := sql.Open("taosSql", dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_, _ = db.Exec("drop user t_sql_notify")
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	_, _ = db.Exec("drop user t_sql_notify")
+	_, err = db.Exec("create user t_sql_notify pass 'notify_123'")
+	if !assert.NoError(t, err) {
+		return
+	}
+	userDB, err := sql.Open("taosSql", "t_sql_notify:notify_123@/tcp(localhost:6030)/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = userDB.Close()
+		assert.NoError(t, err)
+	}()
+	conn, err := userDB.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	events := make(chan common.NotifyEvent, 1)
+	err = conn.Raw(func(driverConn interface{}) error {
+		return driverConn.(Notifier).SetNotifyHandler(func(event common.NotifyEvent) {
+			events <- event
+		})
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = db.Exec("alter user t_sql_notify pass 'test_123'")
+	assert.NoError(t, err)
+	select {
+	case event := <-events:
+		assert.Equal(t, common.NotifyPasswordChanged, event.Type)
+	case <-time.After(time.Second * 5):
+		t.Error("wait for notify event timeout")
+	}
+}
+
+func TestConnectionOptions(t *testing.T) {
+	db, err := sql.Open("taosSql", "root:taosdata@/tcp(localhost:6030)/?connTimezone=Asia%2FShanghai&userApp=test_app&biMode=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	var version string
+	err = db.QueryRow("select server_version()").Scan(&version)
+	assert.NoError(t, err)
+}
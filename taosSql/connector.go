@@ -14,21 +14,32 @@ import (
 )
 
 type connector struct {
-	cfg *Config
+	cfg    *Config
+	locker *thread.Locker
 }
 
 var once = sync.Once{}
 
-// Connect implements driver.Connector interface.
-// Connect returns a connection to the database.
-func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
-	onceInitLock.Do(func() {
-		threads := c.cfg.CgoThread
+func newConnector(cfg *Config) *connector {
+	l := cfg.Locker
+	if l == nil {
+		threads := cfg.CgoThread
 		if threads <= 0 {
 			threads = runtime.NumCPU()
 		}
-		locker = thread.NewLocker(threads)
-	})
+		l = thread.NewLocker(threads)
+	}
+	return &connector{cfg: cfg, locker: l}
+}
+
+// Locker returns the locker limiting the cgo calls of the connections of the connector
+func (c *connector) Locker() *thread.Locker {
+	return c.locker
+}
+
+// Connect implements driver.Connector interface.
+// Connect returns a connection to the database.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 	onceInitHandlerPool.Do(func() {
 		poolSize := c.cfg.CgoAsyncHandlerPoolSize
 		if poolSize <= 0 {
@@ -38,13 +49,14 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 	})
 	var err error
 	tc := &taosConn{
-		cfg: c.cfg,
+		cfg:    c.cfg,
+		locker: c.locker,
 	}
 	if c.cfg.Net == "cfg" && len(c.cfg.ConfigPath) > 0 {
 		once.Do(func() {
-			locker.Lock()
+			c.locker.Lock()
 			code := wrapper.TaosOptions(common.TSDB_OPTION_CONFIGDIR, c.cfg.ConfigPath)
-			locker.Unlock()
+			c.locker.Unlock()
 			if code != 0 {
 				err = errors.NewError(code, wrapper.TaosErrorStr(nil))
 			}
@@ -60,16 +72,21 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 	if len(tc.cfg.Passwd) == 0 {
 		tc.cfg.Passwd = common.DefaultPassword
 	}
-	locker.Lock()
+	c.locker.Lock()
 	err = wrapper.TaosSetConfig(tc.cfg.Params)
-	locker.Unlock()
+	c.locker.Unlock()
 	if err != nil {
 		return nil, err
 	}
-	locker.Lock()
+	c.locker.Lock()
 	tc.taos, err = wrapper.TaosConnect(tc.cfg.Addr, tc.cfg.User, tc.cfg.Passwd, tc.cfg.DbName, tc.cfg.Port)
-	locker.Unlock()
+	c.locker.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	err = tc.setOptions()
 	if err != nil {
+		_ = tc.Close()
 		return nil, err
 	}
 
@@ -1,6 +1,7 @@
 package taosSql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"math/rand"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/taosdata/driver-go/v3/types"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
 // @author: xftan
@@ -234,3 +236,88 @@ func TestAllTypeQueryNull(t *testing.T) {
 	}
 	assert.Equal(t, types.RawMessage(`{"a":"b"}`), *(values[len(values)-1]).(*types.RawMessage))
 }
+
+func TestConnectorLocker(t *testing.T) {
+	cfg := NewConfig()
+	cfg.CgoThread = 2
+	c1, err := NewConnector(cfg)
+	assert.NoError(t, err)
+	c2, err := NewConnector(cfg)
+	assert.NoError(t, err)
+	l1 := c1.(CgoLocker).Locker()
+	l2 := c2.(CgoLocker).Locker()
+	assert.Equal(t, 2, l1.Size())
+	assert.NotSame(t, l1, l2)
+	cfg.Locker = thread.NewLocker(3)
+	c3, err := NewConnector(cfg)
+	assert.NoError(t, err)
+	assert.Same(t, cfg.Locker, c3.(CgoLocker).Locker())
+	db := sql.OpenDB(c3)
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	conn, err := db.Conn(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = conn.Close()
+		assert.NoError(t, err)
+	}()
+	err = conn.Raw(func(driverConn interface{}) error {
+		assert.Same(t, cfg.Locker, driverConn.(CgoLocker).Locker())
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestOpenLocker(t *testing.T) {
+	l1 := defaultOpenLocker(0)
+	l2 := defaultOpenLocker(2)
+	assert.Same(t, l1, l2)
+	assert.Equal(t, 2, l1.Size())
+	// the later cgoThread resizes the shared locker
+	assert.Same(t, l1, defaultOpenLocker(3))
+	assert.Equal(t, 3, l1.Size())
+	assert.Equal(t, 3, defaultOpenLocker(0).Size())
+
+	d := &TDengineDriver{}
+	conn1, err := d.Open(dataSourceName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = conn1.Close()
+		assert.NoError(t, err)
+	}()
+	conn2, err := d.Open(dataSourceName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = conn2.Close()
+		assert.NoError(t, err)
+	}()
+	assert.Same(t, conn1.(CgoLocker).Locker(), conn2.(CgoLocker).Locker())
+}
+
+func TestDBLocker(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Locker = thread.NewLocker(2)
+	c, err := NewConnector(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	db := sql.OpenDB(c)
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	l, err := Locker(context.Background(), db)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Same(t, cfg.Locker, l)
+	assert.Equal(t, 2, l.Stats().Size)
+}
@@ -4,42 +4,88 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"runtime"
 	"sync"
 
+	"github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/wrapper/handler"
 	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
-var locker *thread.Locker
-var onceInitLock = sync.Once{}
 var asyncHandlerPool *handler.HandlerPool
 var onceInitHandlerPool = sync.Once{}
 
+var openLocker *thread.Locker
+var onceInitOpenLocker = sync.Once{}
+
 // TDengineDriver is exported to make the driver directly accessible.
 // In general the driver is used via the database/sql package.
 type TDengineDriver struct{}
 
 // Open new Connection.
 // the DSN string is formatted
+// The connections opened by Open share a package cgo locker, a DSN with cgoThread resizes it.
+// Use NewConnector with Config.Locker to limit the connections separately.
 func (d TDengineDriver) Open(dsn string) (driver.Conn, error) {
 	cfg, err := ParseDSN(dsn)
 	if err != nil {
 		return nil, err
 	}
-	c := &connector{
-		cfg: cfg,
-	}
+	cfg.Locker = defaultOpenLocker(cfg.CgoThread)
+	c := newConnector(cfg)
 
 	return c.Connect(context.Background())
 }
 
+// defaultOpenLocker returns the cgo locker shared by the connections opened by Open,
+// it is resized when threads is set and differs from the current size
+func defaultOpenLocker(threads int) *thread.Locker {
+	onceInitOpenLocker.Do(func() {
+		size := threads
+		if size <= 0 {
+			size = runtime.NumCPU()
+		}
+		openLocker = thread.NewLocker(size)
+	})
+	if threads > 0 && openLocker.Size() != threads {
+		openLocker.SetSize(threads)
+	}
+	return openLocker
+}
+
+// Locker returns the cgo locker of the connections of db opened with the taosSql driver, its Stats reports the
+// holders and the waiters of the cgo calls. A connection of db is taken from the pool to reach the locker.
+func Locker(ctx context.Context, db *sql.DB) (*thread.Locker, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	var l *thread.Locker
+	err = conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(CgoLocker)
+		if !ok {
+			return &errors.TaosError{Code: 0xffff, ErrStr: "not a taosSql connection"}
+		}
+		l = c.Locker()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
 func init() {
 	sql.Register("taosSql", &TDengineDriver{})
 }
 
 // NewConnector returns new driver.Connector.
+// The connections of the connector share a cgo locker, which is cfg.Locker or a new one sized by cfg.CgoThread.
 func NewConnector(cfg *Config) (driver.Connector, error) {
-	return &connector{cfg: cfg}, nil
+	return newConnector(cfg), nil
 }
 
 // OpenConnector implements driver.DriverContext.
@@ -48,7 +94,5 @@ func (d TDengineDriver) OpenConnector(dsn string) (driver.Connector, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &connector{
-		cfg: cfg,
-	}, nil
+	return newConnector(cfg), nil
 }
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/taosdata/driver-go/v3/errors"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
 var (
@@ -48,12 +49,18 @@ type Config struct {
 	ConfigPath              string
 	CgoThread               int
 	CgoAsyncHandlerPoolSize int
+	ConnCharset             string         // Charset of the connection
+	ConnTimezone            string         // Timezone of the connection, it does not affect other connections unlike the timezone config
+	UserIP                  string         // User ip shown by the server
+	UserApp                 string         // User app name shown by the server
+	BIMode                  bool           // Enable the BI mode of the connection
+	Locker                  *thread.Locker // Cgo locker shared by the connectors, a new one sized by CgoThread is created for each connector if nil
 }
 
 // NewConfig creates a new Config and sets default values.
 func NewConfig() *Config {
 	return &Config{
-		Loc:               time.UTC,
+		Loc:               time.Local,
 		InterpolateParams: true,
 	}
 }
@@ -191,6 +198,32 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return &errors.TaosError{Code: 0xffff, ErrStr: "invalid cgoAsyncHandlerPoolSize value: " + value}
 			}
 
+		case "connCharset":
+			if cfg.ConnCharset, err = url.QueryUnescape(value); err != nil {
+				return
+			}
+
+		case "connTimezone":
+			if cfg.ConnTimezone, err = url.QueryUnescape(value); err != nil {
+				return
+			}
+
+		case "userIP":
+			if cfg.UserIP, err = url.QueryUnescape(value); err != nil {
+				return
+			}
+
+		case "userApp":
+			if cfg.UserApp, err = url.QueryUnescape(value); err != nil {
+				return
+			}
+
+		case "biMode":
+			cfg.BIMode, err = strconv.ParseBool(value)
+			if err != nil {
+				return &errors.TaosError{Code: 0xffff, ErrStr: "invalid biMode value: " + value}
+			}
+
 		default:
 			// lazy init
 			if cfg.Params == nil {
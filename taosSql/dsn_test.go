@@ -44,7 +44,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    6030,
 				DbName:                  "dbname",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -67,7 +67,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "dbname",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -85,7 +85,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "dbname",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -103,7 +103,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -121,7 +121,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "wo",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -139,7 +139,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "db",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "/home/taos",
 				CgoThread:               0,
@@ -157,7 +157,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "db",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -216,7 +216,7 @@ func TestParseDsn(t *testing.T) {
 					"maxBinaryDisplayWidth": "30",
 					"tempDir":               "/tmp/",
 				},
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -234,7 +234,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "wo",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               8,
@@ -252,7 +252,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "wo",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               8,
@@ -288,13 +288,35 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "dbname",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       false,
 				ConfigPath:              "",
 				CgoThread:               0,
 				CgoAsyncHandlerPoolSize: 0,
 			},
 		},
+		{
+			name: "connection options",
+			dsn:  "user:passwd@net(:)/dbname?connCharset=UTF-8&connTimezone=Asia%2FShanghai&userIP=192.168.1.1&userApp=app&biMode=true",
+			want: &Config{
+				User:              "user",
+				Passwd:            "passwd",
+				Net:               "net",
+				DbName:            "dbname",
+				Loc:               time.Local,
+				InterpolateParams: true,
+				ConnCharset:       "UTF-8",
+				ConnTimezone:      "Asia/Shanghai",
+				UserIP:            "192.168.1.1",
+				UserApp:           "app",
+				BIMode:            true,
+			},
+		},
+		{
+			name: "invalid biMode",
+			dsn:  "user:passwd@net(:)/dbname?biMode=wrong",
+			errs: "invalid biMode value: wrong",
+		},
 		{
 			name: "special char",
 			dsn:  "!%40%23%24%25%5E%26*()-_%2B%3D%5B%5D%7B%7D%3A%3B%3E%3C%3F%7C~%2C.:!%40%23%24%25%5E%26*()-_%2B%3D%5B%5D%7B%7D%3A%3B%3E%3C%3F%7C~%2C.@net(:)/dbname",
@@ -306,7 +328,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "dbname",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -325,7 +347,7 @@ func TestParseDsn(t *testing.T) {
 				Port:                    0,
 				DbName:                  "dbname",
 				Params:                  nil,
-				Loc:                     time.UTC,
+				Loc:                     time.Local,
 				InterpolateParams:       true,
 				ConfigPath:              "",
 				CgoThread:               0,
@@ -1,9 +1,11 @@
 package taosSql
 
 import (
+	"context"
 	"database/sql/driver"
 	"io"
 	"reflect"
+	"time"
 	"unsafe"
 
 	"github.com/taosdata/driver-go/v3/common"
@@ -11,18 +13,23 @@ import (
 	"github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/wrapper"
 	"github.com/taosdata/driver-go/v3/wrapper/handler"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
 )
 
 type rows struct {
+	ctx         context.Context
 	handler     *handler.Handler
 	rowsHeader  *wrapper.RowsHeader
 	done        bool
+	err         error
 	block       unsafe.Pointer
 	blockOffset int
 	blockSize   int
 	result      unsafe.Pointer
 	precision   int
 	isStmt      bool
+	loc         *time.Location
+	locker      *thread.Locker
 }
 
 func (rs *rows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
@@ -54,9 +61,9 @@ func (rs *rows) Close() error {
 		rs.handler = nil
 	}
 	if !rs.isStmt && rs.result != nil {
-		locker.Lock()
+		rs.locker.Lock()
 		wrapper.TaosFreeResult(rs.result)
-		locker.Unlock()
+		rs.locker.Unlock()
 	}
 	rs.result = nil
 	rs.block = nil
@@ -64,6 +71,9 @@ func (rs *rows) Close() error {
 }
 
 func (rs *rows) Next(dest []driver.Value) error {
+	if rs.err != nil {
+		return rs.err
+	}
 	if rs.done {
 		return io.EOF
 	}
@@ -97,12 +107,16 @@ func (rs *rows) Next(dest []driver.Value) error {
 	if err != nil {
 		return err
 	}
+	common.RowTimeIn(dest, rs.loc)
 	rs.blockOffset++
 	return nil
 }
 
 func (rs *rows) taosFetchBlock() error {
-	result := rs.asyncFetchRows()
+	result, err := rs.asyncFetchRows()
+	if err != nil {
+		return err
+	}
 	if result.N == 0 {
 		rs.blockSize = 0
 		return nil
@@ -118,10 +132,37 @@ func (rs *rows) taosFetchBlock() error {
 	return nil
 }
 
-func (rs *rows) asyncFetchRows() *handler.AsyncResult {
-	locker.Lock()
+func (rs *rows) asyncFetchRows() (*handler.AsyncResult, error) {
+	if err := rs.ctx.Err(); err != nil {
+		_ = rs.Close()
+		rs.err = err
+		return nil, err
+	}
+	rs.locker.Lock()
 	wrapper.TaosFetchRawBlockA(rs.result, rs.handler.Handler)
-	locker.Unlock()
-	r := <-rs.handler.Caller.FetchResult
-	return r
+	rs.locker.Unlock()
+	select {
+	case r := <-rs.handler.Caller.FetchResult:
+		return r, nil
+	case <-rs.ctx.Done():
+		rs.locker.Lock()
+		wrapper.TaosStopQuery(rs.result)
+		rs.locker.Unlock()
+		// the result and the handler are released after the pending fetch callback arrives
+		h, res, isStmt, l := rs.handler, rs.result, rs.isStmt, rs.locker
+		go func() {
+			<-h.Caller.FetchResult
+			if !isStmt {
+				l.Lock()
+				wrapper.TaosFreeResult(res)
+				l.Unlock()
+			}
+			asyncHandlerPool.Put(h)
+		}()
+		rs.handler = nil
+		rs.result = nil
+		rs.block = nil
+		rs.err = rs.ctx.Err()
+		return nil, rs.err
+	}
 }
@@ -1,6 +1,7 @@
 package taosSql
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"reflect"
@@ -13,32 +14,39 @@ import (
 	"github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/types"
 	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/cgo"
+	"github.com/taosdata/driver-go/v3/wrapper/handler"
 )
 
-//Client can't get stmt structure even by reflection.
-//So the sql can't contain unset table name and tags.
-
 type Stmt struct {
 	stmt     unsafe.Pointer
+	handle   cgo.Handle
+	caller   *handler.Stmt2CallBackCaller
 	tc       *taosConn
 	pSql     string
 	isInsert bool
-	cols     []*stmtCommon.StmtField
+	fields   []*stmtCommon.Stmt2AllField
 }
 
 func (stmt *Stmt) Close() error {
 	if stmt.stmt != nil {
-		locker.Lock()
-		wrapper.TaosStmtClose(stmt.stmt)
-		locker.Unlock()
+		stmt.tc.locker.Lock()
+		code := wrapper.TaosStmt2Close(stmt.stmt)
+		stmt.tc.locker.Unlock()
+		handler.GlobalStmt2CallBackCallerPool.Put(stmt.handle)
+		if code != 0 {
+			err := stmt.stmt2Err(code)
+			stmt.stmt = nil
+			return err
+		}
 		stmt.stmt = nil
 	}
 	return nil
 }
 
 func (stmt *Stmt) NumInput() int {
-	if stmt.cols != nil {
-		return len(stmt.cols)
+	if stmt.isInsert {
+		return len(stmt.fields)
 	}
 	return -1
 }
@@ -47,89 +55,91 @@ func (stmt *Stmt) Exec(args []driver.Value) (driver.Result, error) {
 	if stmt.tc == nil || stmt.tc.taos == nil {
 		return nil, driver.ErrBadConn
 	}
-	if len(args) != len(stmt.cols) {
+	if stmt.isInsert && len(args) != len(stmt.fields) {
 		return nil, fmt.Errorf("stmt exec error: wrong number of parameters")
 	}
-	locker.Lock()
-	defer locker.Unlock()
-	code := wrapper.TaosStmtBindParam(stmt.stmt, args)
-	if code != 0 {
-		errStr := wrapper.TaosStmtErrStr(stmt.stmt)
-		return nil, errors.NewError(code, errStr)
-	}
-	code = wrapper.TaosStmtAddBatch(stmt.stmt)
-	if code != 0 {
-		errStr := wrapper.TaosStmtErrStr(stmt.stmt)
-		return nil, errors.NewError(code, errStr)
-	}
-	code = wrapper.TaosStmtExecute(stmt.stmt)
-	if code != 0 {
-		errStr := wrapper.TaosStmtErrStr(stmt.stmt)
-		return nil, errors.NewError(code, errStr)
+	result, err := stmt.execute(args)
+	if err != nil {
+		return nil, err
 	}
-	affectRows := wrapper.TaosStmtAffectedRowsOnce(stmt.stmt)
-	return driver.RowsAffected(affectRows), nil
+	return driver.RowsAffected(result.Affected), nil
 }
 
 func (stmt *Stmt) Query(args []driver.Value) (driver.Rows, error) {
 	if stmt.tc == nil || stmt.tc.taos == nil {
 		return nil, driver.ErrBadConn
 	}
-	locker.Lock()
-	defer locker.Unlock()
-	code := wrapper.TaosStmtBindParam(stmt.stmt, args)
-	if code != 0 {
-		errStr := wrapper.TaosStmtErrStr(stmt.stmt)
-		return nil, errors.NewError(code, errStr)
-	}
-	code = wrapper.TaosStmtAddBatch(stmt.stmt)
-	if code != 0 {
-		errStr := wrapper.TaosStmtErrStr(stmt.stmt)
-		return nil, errors.NewError(code, errStr)
-	}
-	code = wrapper.TaosStmtExecute(stmt.stmt)
-	if code != 0 {
-		errStr := wrapper.TaosStmtErrStr(stmt.stmt)
-		return nil, errors.NewError(code, errStr)
+	result, err := stmt.execute(args)
+	if err != nil {
+		return nil, err
 	}
-	res := wrapper.TaosStmtUseResult(stmt.stmt)
 	handler := asyncHandlerPool.Get()
-	numFields := wrapper.TaosNumFields(res)
-	rowsHeader, err := wrapper.ReadColumn(res, numFields)
+	stmt.tc.locker.Lock()
+	numFields := wrapper.TaosNumFields(result.Res)
+	rowsHeader, err := wrapper.ReadColumn(result.Res, numFields)
 	if err != nil {
+		stmt.tc.locker.Unlock()
+		asyncHandlerPool.Put(handler)
 		return nil, err
 	}
-	precision := wrapper.TaosResultPrecision(res)
+	precision := wrapper.TaosResultPrecision(result.Res)
+	stmt.tc.locker.Unlock()
 	rs := &rows{
+		ctx:        context.Background(),
 		handler:    handler,
 		rowsHeader: rowsHeader,
-		result:     res,
+		result:     result.Res,
 		precision:  precision,
 		isStmt:     true,
+		loc:        stmt.tc.cfg.Loc,
+		locker:     stmt.tc.locker,
 	}
 	return rs, nil
 }
 
+func (stmt *Stmt) execute(args []driver.Value) (*handler.Stmt2Result, error) {
+	var params []*stmtCommon.TaosStmt2BindData
+	if len(args) > 0 {
+		bindData, err := stmtCommon.RowToStmt2BindData(stmt.isInsert, stmt.fields, args)
+		if err != nil {
+			return nil, err
+		}
+		params = []*stmtCommon.TaosStmt2BindData{bindData}
+	}
+	stmt.tc.locker.Lock()
+	if len(params) > 0 {
+		err := wrapper.TaosStmt2BindParam(stmt.stmt, stmt.isInsert, params, stmt.fields, -1)
+		if err != nil {
+			stmt.tc.locker.Unlock()
+			return nil, err
+		}
+	}
+	code := wrapper.TaosStmt2Exec(stmt.stmt)
+	stmt.tc.locker.Unlock()
+	if code != 0 {
+		return nil, stmt.stmt2Err(code)
+	}
+	result := <-stmt.caller.ExecResult
+	if result.N != 0 {
+		return nil, stmt.stmt2Err(result.N)
+	}
+	return result, nil
+}
+
+func (stmt *Stmt) stmt2Err(code int) error {
+	errStr := wrapper.TaosStmt2Error(stmt.stmt)
+	return errors.NewError(code, errStr)
+}
+
 func (stmt *Stmt) CheckNamedValue(v *driver.NamedValue) error {
 	if stmt.isInsert {
-		if stmt.cols == nil {
-			locker.Lock()
-			code, num, fieldsP := wrapper.TaosStmtGetColFields(stmt.stmt)
-			locker.Unlock()
-			if code != 0 {
-				errStr := wrapper.TaosStmtErrStr(stmt.stmt)
-				return errors.NewError(code, errStr)
-			}
-			defer wrapper.TaosStmtReclaimFields(stmt.stmt, fieldsP)
-			stmt.cols = wrapper.StmtParseFields(num, fieldsP)
-		}
-		if v.Ordinal > len(stmt.cols) {
+		if v.Ordinal > len(stmt.fields) {
 			return nil
 		}
 		if v.Value == nil {
 			return nil
 		}
-		switch stmt.cols[v.Ordinal-1].FieldType {
+		switch stmt.fields[v.Ordinal-1].FieldType {
 		case common.TSDB_DATA_TYPE_NULL:
 			v.Value = nil
 		case common.TSDB_DATA_TYPE_BOOL:
@@ -330,29 +340,29 @@ func (stmt *Stmt) CheckNamedValue(v *driver.NamedValue) error {
 			if is {
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 				return nil
 			}
 			rv := reflect.ValueOf(v.Value)
 			switch rv.Kind() {
 			case reflect.Float32, reflect.Float64:
-				t := common.TimestampConvertToTime(int64(rv.Float()), int(stmt.cols[v.Ordinal-1].Precision))
+				t := common.TimestampConvertToTime(int64(rv.Float()), int(stmt.fields[v.Ordinal-1].Precision))
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				t := common.TimestampConvertToTime(rv.Int(), int(stmt.cols[v.Ordinal-1].Precision))
+				t := common.TimestampConvertToTime(rv.Int(), int(stmt.fields[v.Ordinal-1].Precision))
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				t := common.TimestampConvertToTime(int64(rv.Uint()), int(stmt.cols[v.Ordinal-1].Precision))
+				t := common.TimestampConvertToTime(int64(rv.Uint()), int(stmt.fields[v.Ordinal-1].Precision))
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 			case reflect.String:
 				t, err := time.Parse(time.RFC3339Nano, rv.String())
@@ -361,7 +371,7 @@ func (stmt *Stmt) CheckNamedValue(v *driver.NamedValue) error {
 				}
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 			default:
 				return fmt.Errorf("CheckNamedValue:%v can not convert to timestamp", v)
@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/errors"
 	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/handler"
 )
 
 // @author: xftan
@@ -203,6 +205,75 @@ func TestStmtQuery(t *testing.T) {
 	assert.Equal(t, 1, count)
 }
 
+func TestStmtInsertWithTags(t *testing.T) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	defer func() {
+		_, err = db.Exec("drop database if exists test_stmt_driver_tags")
+		assert.NoError(t, err)
+	}()
+	_, err = db.Exec("create database if not exists test_stmt_driver_tags")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = db.Exec("create stable if not exists test_stmt_driver_tags.stb(ts timestamp,v int) tags(t1 int,t2 nchar(20))")
+	if !assert.NoError(t, err) {
+		return
+	}
+	stmt, err := db.Prepare("insert into ? using test_stmt_driver_tags.stb tags(?,?) values(?,?)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = stmt.Close()
+		assert.NoError(t, err)
+	}()
+	now := time.Now()
+	result, err := stmt.Exec("test_stmt_driver_tags.ctb1", 1, "tag1", now, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	affected, err := result.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	result, err = stmt.Exec("test_stmt_driver_tags.ctb2", 2, "tag2", now, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	affected, err = result.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	_, err = stmt.Exec("test_stmt_driver_tags.ctb3", 3, "tag3", now)
+	assert.Error(t, err)
+
+	queryStmt, err := db.Prepare("select tbname,v,t2 from test_stmt_driver_tags.stb where t1 = ? and ts = ?")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = queryStmt.Close()
+		assert.NoError(t, err)
+	}()
+	var (
+		tbName string
+		v      int32
+		t2     string
+	)
+	err = queryStmt.QueryRow(2, now).Scan(&tbName, &v, &t2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "ctb2", tbName)
+	assert.Equal(t, int32(2), v)
+	assert.Equal(t, "tag2", t2)
+}
+
 // @author: xftan
 // @date: 2023/10/13 11:22
 // @description: test stmt convert
@@ -2191,7 +2262,9 @@ func TestStmtConvertQuery(t *testing.T) {
 func TestWrongStmt(t *testing.T) {
 	d := &TDengineDriver{}
 	conn, err := d.Open(dataSourceName)
-	assert.NoError(t, err)
+	if !assert.NoError(t, err) {
+		return
+	}
 	defer func() {
 		err = conn.Close()
 		assert.NoError(t, err)
@@ -2199,21 +2272,27 @@ func TestWrongStmt(t *testing.T) {
 	c := conn.(*taosConn)
 	cPointer := c.taos
 	c.taos = nil
-	defer func() {
-		c.taos = cPointer
-	}()
 	_, err = c.Prepare("")
+	c.taos = cPointer
+	assert.Equal(t, errors.ErrTscInvalidConnection, err)
+
+	_, err = c.Prepare("insert into not_exist_db.not_exist_table values(?,?)")
 	assert.Error(t, err)
 
 	p, err := wrapper.TaosConnect("", "root", "taosdata", "", 0)
-	assert.NoError(t, err)
+	if !assert.NoError(t, err) {
+		return
+	}
 	defer wrapper.TaosClose(p)
-	stmt := wrapper.TaosStmtInit(p)
-	code := wrapper.TaosStmtPrepare(stmt, "")
-	err = checkStmtError(code, stmt)
-	assert.NoError(t, err)
-	code = wrapper.TaosStmtExecute(stmt)
-	assert.NotEqual(t, 0, code)
-	err = checkStmtError(code, stmt)
+	handle, _ := handler.GlobalStmt2CallBackCallerPool.Get()
+	stmt := wrapper.TaosStmt2Init(p, 0xcc, false, false, handle)
+	code := wrapper.TaosStmt2Prepare(stmt, "insert into not_exist_db.not_exist_table values(?,?)")
+	if !assert.NotEqual(t, 0, code) {
+		wrapper.TaosStmt2Close(stmt)
+		handler.GlobalStmt2CallBackCallerPool.Put(handle)
+		return
+	}
+	// checkStmtError closes the stmt and releases the handle
+	err = c.checkStmtError(code, stmt, handle)
 	assert.Error(t, err)
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql/driver"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 	"github.com/gorilla/websocket"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 	stmtCommon "github.com/taosdata/driver-go/v3/common/stmt"
 	taosErrors "github.com/taosdata/driver-go/v3/errors"
 )
@@ -26,13 +28,29 @@ const (
 	WSConnect    = "conn"
 	WSFreeResult = "free_result"
 
-	STMTInit         = "init"
-	STMTPrepare      = "prepare"
-	STMTAddBatch     = "add_batch"
-	STMTExec         = "exec"
-	STMTClose        = "close"
+	STMT2Init    = "stmt2_init"
+	STMT2Prepare = "stmt2_prepare"
+	STMT2Exec    = "stmt2_exec"
+	STMT2Result  = "stmt2_result"
+	STMT2Close   = "stmt2_close"
+)
+
+// The stmt actions are kept for compatibility, taosWS prepares the statements with stmt2.
+const (
+	// Deprecated: use STMT2Init instead.
+	STMTInit = "init"
+	// Deprecated: use STMT2Prepare instead.
+	STMTPrepare = "prepare"
+	// Deprecated: stmt2 binds the whole batch at once.
+	STMTAddBatch = "add_batch"
+	// Deprecated: use STMT2Exec instead.
+	STMTExec = "exec"
+	// Deprecated: use STMT2Close instead.
+	STMTClose = "close"
+	// Deprecated: the fields are returned by STMT2Prepare.
 	STMTGetColFields = "get_col_fields"
-	STMTUseResult    = "use_result"
+	// Deprecated: use STMT2Result instead.
+	STMTUseResult = "use_result"
 )
 
 const (
@@ -60,6 +78,12 @@ type taosConn struct {
 	endpoint     string
 	closed       uint32
 	closeCh      chan struct{}
+	// lateResponses holds the request IDs whose responses are no longer waited for,
+	// the handler is called when the late response arrives.
+	lateResponses map[uint64]func(mt int, msg []byte)
+	// onBadConn is called once when the connection is broken, used to mark the endpoint unhealthy.
+	onBadConn     func()
+	badConnNotify sync.Once
 }
 
 type message struct {
@@ -68,21 +92,24 @@ type message struct {
 	err     error
 }
 
-func newTaosConn(cfg *Config) (*taosConn, error) {
+func newTaosConn(cfg *Config, addr string, onBadConn func()) (*taosConn, error) {
 	endpointUrl := &url.URL{
 		Scheme: cfg.Net,
-		Host:   fmt.Sprintf("%s:%d", cfg.Addr, cfg.Port),
+		Host:   addr,
 		Path:   "/ws",
 	}
 	if cfg.Token != "" {
 		endpointUrl.RawQuery = fmt.Sprintf("token=%s", cfg.Token)
 	}
-	endpoint := endpointUrl.String()
+	wsURL := endpointUrl.String()
 	dialer := common.DefaultDialer
 	dialer.EnableCompression = cfg.EnableCompression
-	ws, _, err := dialer.Dial(endpoint, nil)
+	if cfg.TLS != nil {
+		dialer.TLSClientConfig = cfg.TLS.Clone()
+	}
+	ws, _, err := dialer.Dial(wsURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, &endpoint.DialError{Err: err}
 	}
 	ws.EnableWriteCompression(cfg.EnableCompression)
 	err = ws.SetReadDeadline(time.Now().Add(common.DefaultPongWait))
@@ -94,14 +121,16 @@ func newTaosConn(cfg *Config) (*taosConn, error) {
 		return nil
 	})
 	tc := &taosConn{
-		buf:          &bytes.Buffer{},
-		client:       ws,
-		readTimeout:  cfg.ReadTimeout,
-		writeTimeout: cfg.WriteTimeout,
-		cfg:          cfg,
-		endpoint:     endpoint,
-		closeCh:      make(chan struct{}),
-		messageChan:  make(chan *message, 10),
+		buf:           &bytes.Buffer{},
+		client:        ws,
+		readTimeout:   cfg.ReadTimeout,
+		writeTimeout:  cfg.WriteTimeout,
+		cfg:           cfg,
+		endpoint:      wsURL,
+		closeCh:       make(chan struct{}),
+		messageChan:   make(chan *message, 10),
+		lateResponses: make(map[uint64]func(mt int, msg []byte)),
+		onBadConn:     onBadConn,
 	}
 
 	go tc.ping()
@@ -109,6 +138,7 @@ func newTaosConn(cfg *Config) (*taosConn, error) {
 	err = tc.connect()
 	if err != nil {
 		_ = tc.Close()
+		return nil, err
 	}
 	return tc, nil
 }
@@ -139,6 +169,9 @@ func (tc *taosConn) read() {
 		}
 		if err != nil {
 			tc.messageError = NewBadConnError(err)
+			if !tc.isClosed() {
+				tc.notifyBadConn()
+			}
 			break
 		}
 		if tc.isClosed() {
@@ -191,13 +224,13 @@ func (tc *taosConn) PrepareContext(ctx context.Context, query string) (driver.St
 	if err != nil {
 		return nil, err
 	}
-	stmtID, err := tc.stmtInit(reqID)
+	stmtID, err := tc.stmt2Init(reqID)
 	if err != nil {
 		return nil, err
 	}
-	isInsert, err := tc.stmtPrepare(stmtID, query)
+	isInsert, fields, err := tc.stmt2Prepare(stmtID, query)
 	if err != nil {
-		_ = tc.stmtClose(stmtID)
+		_ = tc.stmt2Close(stmtID)
 		return nil, err
 	}
 	stmt := &Stmt{
@@ -205,12 +238,13 @@ func (tc *taosConn) PrepareContext(ctx context.Context, query string) (driver.St
 		stmtID:   stmtID,
 		isInsert: isInsert,
 		pSql:     query,
+		fields:   fields,
 	}
 	return stmt, nil
 }
 
-func (tc *taosConn) stmtInit(reqID uint64) (uint64, error) {
-	req := &StmtInitReq{
+func (tc *taosConn) stmt2Init(reqID uint64) (uint64, error) {
+	req := &Stmt2InitReq{
 		ReqID: reqID,
 	}
 	reqArgs, err := json.Marshal(req)
@@ -218,7 +252,7 @@ func (tc *taosConn) stmtInit(reqID uint64) (uint64, error) {
 		return 0, err
 	}
 	action := &WSAction{
-		Action: STMTInit,
+		Action: STMT2Init,
 		Args:   reqArgs,
 	}
 	tc.buf.Reset()
@@ -230,8 +264,8 @@ func (tc *taosConn) stmtInit(reqID uint64) (uint64, error) {
 	if err != nil {
 		return 0, err
 	}
-	var resp StmtInitResp
-	err = tc.readTo(&resp, reqID)
+	var resp Stmt2InitResp
+	err = tc.readTo(context.Background(), &resp, reqID)
 	err = handleResponseError(err, resp.Code, resp.Message)
 	if err != nil {
 		return 0, err
@@ -239,42 +273,46 @@ func (tc *taosConn) stmtInit(reqID uint64) (uint64, error) {
 	return resp.StmtID, nil
 }
 
-func (tc *taosConn) stmtPrepare(stmtID uint64, sql string) (bool, error) {
+func (tc *taosConn) stmt2Prepare(stmtID uint64, sql string) (bool, []*stmtCommon.Stmt2AllField, error) {
 	reqID := uint64(common.GetReqID())
-	req := &StmtPrepareRequest{
-		ReqID:  reqID,
-		StmtID: stmtID,
-		SQL:    sql,
+	req := &Stmt2PrepareReq{
+		ReqID:     reqID,
+		StmtID:    stmtID,
+		SQL:       sql,
+		GetFields: true,
 	}
 	reqArgs, err := json.Marshal(req)
 	if err != nil {
-		return false, err
+		return false, nil, err
 	}
 	action := &WSAction{
-		Action: STMTPrepare,
+		Action: STMT2Prepare,
 		Args:   reqArgs,
 	}
 	tc.buf.Reset()
 	err = jsonI.NewEncoder(tc.buf).Encode(action)
 	if err != nil {
-		return false, err
+		return false, nil, err
 	}
 	err = tc.writeText(tc.buf.Bytes())
 	if err != nil {
-		return false, err
+		return false, nil, err
 	}
-	var resp StmtPrepareResponse
-	err = tc.readTo(&resp, reqID)
+	var resp Stmt2PrepareResp
+	err = tc.readTo(context.Background(), &resp, reqID)
 	err = handleResponseError(err, resp.Code, resp.Message)
 	if err != nil {
-		return false, err
+		return false, nil, err
+	}
+	if !resp.IsInsert {
+		return false, nil, nil
 	}
-	return resp.IsInsert, nil
+	return true, resp.Fields, nil
 }
 
-func (tc *taosConn) stmtClose(stmtID uint64) error {
+func (tc *taosConn) stmt2Close(stmtID uint64) error {
 	reqID := uint64(common.GetReqID())
-	req := &StmtCloseRequest{
+	req := &Stmt2CloseReq{
 		ReqID:  reqID,
 		StmtID: stmtID,
 	}
@@ -283,7 +321,7 @@ func (tc *taosConn) stmtClose(stmtID uint64) error {
 		return err
 	}
 	action := &WSAction{
-		Action: STMTClose,
+		Action: STMT2Close,
 		Args:   reqArgs,
 	}
 	tc.buf.Reset()
@@ -295,54 +333,27 @@ func (tc *taosConn) stmtClose(stmtID uint64) error {
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func (tc *taosConn) stmtGetColFields(stmtID uint64) ([]*stmtCommon.StmtField, error) {
-	reqID := uint64(common.GetReqID())
-	req := &StmtGetColFieldsRequest{
-		ReqID:  reqID,
-		StmtID: stmtID,
-	}
-	reqArgs, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
-	}
-	action := &WSAction{
-		Action: STMTGetColFields,
-		Args:   reqArgs,
-	}
-	tc.buf.Reset()
-	err = jsonI.NewEncoder(tc.buf).Encode(action)
-	if err != nil {
-		return nil, err
-	}
-	err = tc.writeText(tc.buf.Bytes())
-	if err != nil {
-		return nil, err
-	}
-	var resp StmtGetColFieldsResponse
-	err = tc.readTo(&resp, reqID)
-	err = handleResponseError(err, resp.Code, resp.Message)
-	if err != nil {
-		return nil, err
-	}
-	return resp.Fields, nil
+	var resp Stmt2CloseResp
+	err = tc.readTo(context.Background(), &resp, reqID)
+	return handleResponseError(err, resp.Code, resp.Message)
 }
 
-func (tc *taosConn) stmtBindParam(stmtID uint64, block []byte) error {
+func (tc *taosConn) stmt2Bind(stmtID uint64, data []byte) error {
 	reqID := uint64(common.GetReqID())
 	tc.buf.Reset()
 	WriteUint64(tc.buf, reqID)
 	WriteUint64(tc.buf, stmtID)
-	WriteUint64(tc.buf, BindMessage)
-	tc.buf.Write(block)
+	WriteUint64(tc.buf, Stmt2BindMessage)
+	WriteUint16(tc.buf, Stmt2BindProtocolVersion1)
+	// col index, -1 means bind all columns
+	WriteUint32(tc.buf, 0xffffffff)
+	tc.buf.Write(data)
 	err := tc.writeBinary(tc.buf.Bytes())
 	if err != nil {
 		return err
 	}
-	var resp StmtBindResponse
-	err = tc.readTo(&resp, reqID)
+	var resp Stmt2BindResp
+	err = tc.readTo(context.Background(), &resp, reqID)
 	return handleResponseError(err, resp.Code, resp.Message)
 }
 
@@ -369,37 +380,9 @@ func WriteUint16(buffer *bytes.Buffer, v uint16) {
 	buffer.WriteByte(byte(v >> 8))
 }
 
-func (tc *taosConn) stmtAddBatch(stmtID uint64) error {
-	reqID := uint64(common.GetReqID())
-	req := &StmtAddBatchRequest{
-		ReqID:  reqID,
-		StmtID: stmtID,
-	}
-	reqArgs, err := json.Marshal(req)
-	if err != nil {
-		return err
-	}
-	action := &WSAction{
-		Action: STMTAddBatch,
-		Args:   reqArgs,
-	}
-	tc.buf.Reset()
-	err = jsonI.NewEncoder(tc.buf).Encode(action)
-	if err != nil {
-		return err
-	}
-	err = tc.writeText(tc.buf.Bytes())
-	if err != nil {
-		return err
-	}
-	var resp StmtAddBatchResponse
-	err = tc.readTo(&resp, reqID)
-	return handleResponseError(err, resp.Code, resp.Message)
-}
-
-func (tc *taosConn) stmtExec(stmtID uint64) (int, error) {
+func (tc *taosConn) stmt2Exec(stmtID uint64) (int, error) {
 	reqID := uint64(common.GetReqID())
-	req := &StmtExecRequest{
+	req := &Stmt2ExecReq{
 		ReqID:  reqID,
 		StmtID: stmtID,
 	}
@@ -408,7 +391,7 @@ func (tc *taosConn) stmtExec(stmtID uint64) (int, error) {
 		return 0, err
 	}
 	action := &WSAction{
-		Action: STMTExec,
+		Action: STMT2Exec,
 		Args:   reqArgs,
 	}
 	tc.buf.Reset()
@@ -420,8 +403,8 @@ func (tc *taosConn) stmtExec(stmtID uint64) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	var resp StmtExecResponse
-	err = tc.readTo(&resp, reqID)
+	var resp Stmt2ExecResp
+	err = tc.readTo(context.Background(), &resp, reqID)
 	err = handleResponseError(err, resp.Code, resp.Message)
 	if err != nil {
 		return 0, err
@@ -429,9 +412,9 @@ func (tc *taosConn) stmtExec(stmtID uint64) (int, error) {
 	return resp.Affected, nil
 }
 
-func (tc *taosConn) stmtUseResult(stmtID uint64) (*rows, error) {
+func (tc *taosConn) stmt2UseResult(stmtID uint64) (*rows, error) {
 	reqID := uint64(common.GetReqID())
-	req := &StmtUseResultRequest{
+	req := &Stmt2UseResultReq{
 		ReqID:  reqID,
 		StmtID: stmtID,
 	}
@@ -440,7 +423,7 @@ func (tc *taosConn) stmtUseResult(stmtID uint64) (*rows, error) {
 		return nil, err
 	}
 	action := &WSAction{
-		Action: STMTUseResult,
+		Action: STMT2Result,
 		Args:   reqArgs,
 	}
 	tc.buf.Reset()
@@ -452,22 +435,25 @@ func (tc *taosConn) stmtUseResult(stmtID uint64) (*rows, error) {
 	if err != nil {
 		return nil, err
 	}
-	var resp StmtUseResultResponse
-	err = tc.readTo(&resp, reqID)
+	var resp Stmt2UseResultResp
+	err = tc.readTo(context.Background(), &resp, reqID)
 	err = handleResponseError(err, resp.Code, resp.Message)
 	if err != nil {
 		return nil, err
 	}
 	rs := &rows{
-		buf:           &bytes.Buffer{},
-		conn:          tc,
-		resultID:      resp.ResultID,
-		fieldsCount:   resp.FieldsCount,
-		fieldsNames:   resp.FieldsNames,
-		fieldsTypes:   resp.FieldsTypes,
-		fieldsLengths: resp.FieldsLengths,
-		precision:     resp.Precision,
-		isStmt:        true,
+		ctx:              context.Background(),
+		buf:              &bytes.Buffer{},
+		conn:             tc,
+		resultID:         resp.ResultID,
+		fieldsCount:      resp.FieldsCount,
+		fieldsNames:      resp.FieldsNames,
+		fieldsTypes:      resp.FieldsTypes,
+		fieldsLengths:    resp.FieldsLengths,
+		precision:        resp.Precision,
+		fieldsPrecisions: resp.FieldsPrecisions,
+		fieldsScales:     resp.FieldsScales,
+		isStmt:           true,
 	}
 	return rs, nil
 }
@@ -503,6 +489,7 @@ func (tc *taosConn) queryCtx(ctx context.Context, query string, args []driver.Na
 		return nil, NotQueryError
 	}
 	rs := &rows{
+		ctx:              ctx,
 		buf:              &bytes.Buffer{},
 		conn:             tc,
 		resultID:         resp.ID,
@@ -530,7 +517,7 @@ func (tc *taosConn) doQuery(ctx context.Context, query string, args []driver.Nam
 			return nil, driver.ErrSkip
 		}
 		// try client-side prepare to reduce round trip
-		prepared, err := common.InterpolateParams(query, args)
+		prepared, err := common.InterpolateParamsWithLocation(query, args, tc.cfg.Loc)
 		if err != nil {
 			return nil, err
 		}
@@ -549,8 +536,20 @@ func (tc *taosConn) doQuery(ctx context.Context, query string, args []driver.Nam
 		return nil, err
 	}
 	var resp WSQueryResp
-	err = tc.readTo(&resp, reqID)
-	if err != nil {
+	err = tc.readTo(ctx, &resp, reqID)
+	if err != nil {
+		if _, late := tc.lateResponses[reqID]; late {
+			// free the result when the abandoned query response arrives
+			tc.lateResponses[reqID] = func(mt int, msg []byte) {
+				if mt != websocket.TextMessage {
+					return
+				}
+				var lateResp WSQueryResp
+				if jsonI.Unmarshal(msg, &lateResp) == nil && lateResp.Code == 0 && lateResp.ID != 0 {
+					_ = tc.freeResult(lateResp.ID)
+				}
+			}
+		}
 		return nil, err
 	}
 	return &resp, nil
@@ -589,7 +588,7 @@ func (tc *taosConn) connect() error {
 		return err
 	}
 	var resp WSConnectResp
-	err = tc.readTo(&resp, redID)
+	err = tc.readTo(context.Background(), &resp, redID)
 	return handleResponseError(err, resp.Code, resp.Message)
 }
 
@@ -620,13 +619,21 @@ func (tc *taosConn) write(messageType int, data []byte) error {
 	}
 	err = tc.client.WriteMessage(messageType, data)
 	if err != nil {
+		tc.notifyBadConn()
 		return NewBadConnErrorWithCtx(err, string(data))
 	}
 	return nil
 }
 
-func (tc *taosConn) readTo(to interface{}, expectRedID uint64) error {
-	mt, respBytes, err := tc.readResponse()
+func (tc *taosConn) notifyBadConn() {
+	if tc.onBadConn == nil {
+		return
+	}
+	tc.badConnNotify.Do(tc.onBadConn)
+}
+
+func (tc *taosConn) readTo(ctx context.Context, to interface{}, expectRedID uint64) error {
+	mt, respBytes, err := tc.readResponse(ctx, expectRedID)
 	if err != nil {
 		return err
 	}
@@ -645,8 +652,8 @@ func (tc *taosConn) readTo(to interface{}, expectRedID uint64) error {
 	return nil
 }
 
-func (tc *taosConn) readBytes() ([]byte, error) {
-	mt, respBytes, err := tc.readResponse()
+func (tc *taosConn) readBytes(ctx context.Context, expectRedID uint64) ([]byte, error) {
+	mt, respBytes, err := tc.readResponse(ctx, expectRedID)
 	if err != nil {
 		return nil, err
 	}
@@ -656,28 +663,90 @@ func (tc *taosConn) readBytes() ([]byte, error) {
 	return respBytes, err
 }
 
-func (tc *taosConn) readResponse() (int, []byte, error) {
+// readResponse waits for the response of the request, bounded by both the context and the read timeout.
+// When the wait is abandoned the request is recorded so that its late response is dropped
+// and the connection stays usable.
+func (tc *taosConn) readResponse(ctx context.Context, reqID uint64) (int, []byte, error) {
 	if tc.isClosed() {
 		return 0, nil, driver.ErrBadConn
 	}
 	if tc.messageError != nil {
 		return 0, nil, tc.messageError
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), tc.readTimeout)
-	defer cancel()
-	select {
-	case <-tc.closeCh:
-		return 0, nil, driver.ErrBadConn
-	case msg := <-tc.messageChan:
-		if msg.err != nil {
-			return 0, nil, NewBadConnError(msg.err)
+	timer := time.NewTimer(tc.readTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-tc.closeCh:
+			return 0, nil, driver.ErrBadConn
+		case msg := <-tc.messageChan:
+			if msg.err != nil {
+				return 0, nil, NewBadConnError(msg.err)
+			}
+			if tc.handleLateResponse(msg) {
+				continue
+			}
+			return msg.mt, msg.message, nil
+		case <-timer.C:
+			tc.lateResponses[reqID] = nil
+			return 0, nil, ReadTimeoutError
+		case <-ctx.Done():
+			tc.lateResponses[reqID] = nil
+			return 0, nil, ctx.Err()
 		}
-		return msg.mt, msg.message, nil
-	case <-ctx.Done():
-		return 0, nil, NewBadConnError(ReadTimeoutError)
 	}
 }
 
+// handleLateResponse reports whether the message is the response of an abandoned request.
+func (tc *taosConn) handleLateResponse(msg *message) bool {
+	if len(tc.lateResponses) == 0 {
+		return false
+	}
+	var reqID uint64
+	switch msg.mt {
+	case websocket.TextMessage:
+		reqID = jsonI.Get(msg.message, "req_id").ToUint64()
+	case websocket.BinaryMessage:
+		// fetch raw block response: reserve(8) action(8) version(2) time(8) req_id(8)
+		if len(msg.message) < 34 {
+			return false
+		}
+		reqID = binary.LittleEndian.Uint64(msg.message[26:])
+	default:
+		return false
+	}
+	handler, late := tc.lateResponses[reqID]
+	if !late {
+		return false
+	}
+	delete(tc.lateResponses, reqID)
+	if handler != nil {
+		handler(msg.mt, msg.message)
+	}
+	return true
+}
+
+func (tc *taosConn) freeResult(resultID uint64) error {
+	req := &WSFreeResultReq{
+		ReqID: uint64(common.GetReqID()),
+		ID:    resultID,
+	}
+	args, err := jsonI.Marshal(req)
+	if err != nil {
+		return err
+	}
+	action := &WSAction{
+		Action: WSFreeResult,
+		Args:   args,
+	}
+	buf := &bytes.Buffer{}
+	err = jsonI.NewEncoder(buf).Encode(action)
+	if err != nil {
+		return err
+	}
+	return tc.writeText(buf.Bytes())
+}
+
 func formatBytes(bs []byte) string {
 	if len(bs) == 0 {
 		return ""
@@ -2,9 +2,15 @@ package taosWS
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	taosErrors "github.com/taosdata/driver-go/v3/errors"
 )
@@ -62,7 +68,7 @@ func TestBadConnection(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ParseDSN error: %v", err)
 	}
-	conn, err := newTaosConn(cfg)
+	conn, err := newTaosConn(cfg, fmt.Sprintf("%s:%d", cfg.Addr, cfg.Port), nil)
 	if err != nil {
 		t.Fatalf("newTaosConn error: %v", err)
 	}
@@ -106,7 +112,7 @@ func TestBegin(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ParseDSN error: %v", err)
 	}
-	conn, err := newTaosConn(cfg)
+	conn, err := newTaosConn(cfg, fmt.Sprintf("%s:%d", cfg.Addr, cfg.Port), nil)
 	if err != nil {
 		t.Fatalf("newTaosConn error: %v", err)
 	}
@@ -119,3 +125,80 @@ func TestBegin(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, tx)
 }
+
+func TestReadResponseDropLateResponse(t *testing.T) {
+	tc := &taosConn{
+		readTimeout:   time.Second,
+		messageChan:   make(chan *message, 10),
+		closeCh:       make(chan struct{}),
+		lateResponses: make(map[uint64]func(mt int, msg []byte)),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := tc.readResponse(ctx, 1)
+	assert.Equal(t, context.Canceled, err)
+	assert.NotErrorIs(t, err, driver.ErrBadConn)
+
+	lateBlock := make([]byte, 34)
+	binary.LittleEndian.PutUint64(lateBlock[26:], 1)
+	tc.messageChan <- &message{mt: websocket.BinaryMessage, message: lateBlock}
+	tc.messageChan <- &message{mt: websocket.TextMessage, message: []byte(`{"req_id":2}`)}
+	mt, msg, err := tc.readResponse(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, websocket.TextMessage, mt)
+	assert.Equal(t, []byte(`{"req_id":2}`), msg)
+	assert.Empty(t, tc.lateResponses)
+
+	var lateMsg []byte
+	tc.lateResponses[3] = func(mt int, msg []byte) {
+		lateMsg = msg
+	}
+	tc.messageChan <- &message{mt: websocket.TextMessage, message: []byte(`{"req_id":3}`)}
+	tc.messageChan <- &message{mt: websocket.TextMessage, message: []byte(`{"req_id":4}`)}
+	_, msg, err = tc.readResponse(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`{"req_id":4}`), msg)
+	assert.Equal(t, []byte(`{"req_id":3}`), lateMsg)
+}
+
+func TestQueryContextCancel(t *testing.T) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = conn.Close()
+		assert.NoError(t, err)
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = conn.QueryContext(ctx, "select 1")
+	assert.Equal(t, context.Canceled, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	rs, err := conn.QueryContext(ctx, "select * from information_schema.ins_columns")
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	cancel()
+	for rs.Next() {
+	}
+	assert.Equal(t, context.Canceled, rs.Err())
+	err = rs.Close()
+	assert.NoError(t, err)
+
+	// the connection is still usable after cancellation
+	var v int64
+	err = conn.QueryRowContext(context.Background(), "select 1").Scan(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+}
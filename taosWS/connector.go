@@ -3,12 +3,19 @@ package taosWS
 import (
 	"context"
 	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 )
 
 type connector struct {
-	cfg *Config
+	cfg          *Config
+	balancerOnce sync.Once
+	balancer     *endpoint.Balancer
+	balancerErr  error
 }
 
 // Connect implements driver.Connector interface.
@@ -24,7 +31,9 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 	if c.cfg.Port == 0 {
 		c.cfg.Port = common.DefaultHttpPort
 	}
-	if len(c.cfg.Net) == 0 {
+	if c.cfg.TLS != nil && (len(c.cfg.Net) == 0 || c.cfg.Net == "ws") {
+		c.cfg.Net = "wss"
+	} else if len(c.cfg.Net) == 0 {
 		c.cfg.Net = "ws"
 	}
 	if len(c.cfg.Addr) == 0 {
@@ -36,8 +45,33 @@ func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
 	if c.cfg.WriteTimeout == 0 {
 		c.cfg.WriteTimeout = common.DefaultWriteWait
 	}
-	tc, err := newTaosConn(c.cfg)
-	return tc, err
+	if len(c.cfg.Endpoints) == 0 {
+		return newTaosConn(c.cfg, fmt.Sprintf("%s:%d", c.cfg.Addr, c.cfg.Port), nil)
+	}
+	c.balancerOnce.Do(func() {
+		c.balancer, c.balancerErr = endpoint.NewBalancer(endpoint.FillDefaultPort(c.cfg.Endpoints, common.DefaultHttpPort), c.cfg.EndpointPolicy)
+	})
+	if c.balancerErr != nil {
+		return nil, c.balancerErr
+	}
+	var tc *taosConn
+	err := c.balancer.Do(func(addr string) error {
+		var err error
+		tc, err = newTaosConn(c.cfg, addr, func() { c.balancer.MarkFailed(addr) })
+		return err
+	}, isFailoverError)
+	if err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// isFailoverError reports whether the next endpoint should be tried, errors such as authentication failure are
+// returned directly since they happen on every endpoint.
+func isFailoverError(err error) bool {
+	var dialErr *endpoint.DialError
+	var badConnErr *BadConnError
+	return errors.As(err, &dialErr) || errors.As(err, &badConnErr)
 }
 
 // Driver implements driver.Connector interface.
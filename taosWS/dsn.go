@@ -1,18 +1,22 @@
 package taosWS
 
 import (
+	"crypto/tls"
+	"net"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 	"github.com/taosdata/driver-go/v3/errors"
 )
 
 var (
 	ErrInvalidDSNUnescaped = &errors.TaosError{Code: 0xffff, ErrStr: "invalid DSN: did you forget to escape a param value?"}
-	ErrInvalidDSNAddr      = &errors.TaosError{Code: 0xffff, ErrStr: "invalid DSN: network address not terminated (missing closing brace)"}
-	ErrInvalidDSNPort      = &errors.TaosError{Code: 0xffff, ErrStr: "invalid DSN: network port is not a valid number"}
+	ErrInvalidDSNAddr      = endpoint.ErrInvalidAddr
+	ErrInvalidDSNPort      = endpoint.ErrInvalidPort
 	ErrInvalidDSNNoSlash   = &errors.TaosError{Code: 0xffff, ErrStr: "invalid DSN: missing the slash separating the database name"}
 )
 
@@ -32,6 +36,11 @@ type Config struct {
 	EnableCompression bool              // Enable write compression
 	ReadTimeout       time.Duration     // read message timeout
 	WriteTimeout      time.Duration     // write message timeout
+	Loc               *time.Location    // Location for time.Time values, the local time zone is used when nil
+	TLSConfig         string            // TLS configuration name registered by common.RegisterTLSConfig
+	TLS               *tls.Config       // TLS configuration, resolved from TLSConfig when parsing DSN
+	Endpoints         []string          // Network addresses host:port when more than one is specified
+	EndpointPolicy    string            // Policy to choose the endpoint, one of roundRobin, random and firstAvailable
 }
 
 // NewConfig creates a new Config and sets default values.
@@ -85,7 +94,19 @@ func ParseDSN(dsn string) (cfg *Config, err error) {
 							}
 							//return nil, errInvalidDSNAddr
 						}
-						strList := strings.Split(dsn[k+1:i-1], ":")
+						addrList := strings.Split(dsn[k+1:i-1], ",")
+						if len(addrList) > 1 {
+							// multiple endpoints: ws(host1:port1,host2:port2), IPv6 hosts are enclosed in square brackets
+							cfg.Endpoints, err = endpoint.ParseEndpoints(addrList)
+							if err != nil {
+								return nil, err
+							}
+							host, port, _ := net.SplitHostPort(cfg.Endpoints[0])
+							cfg.Addr = host
+							cfg.Port, _ = strconv.Atoi(port)
+							break
+						}
+						strList := strings.Split(addrList[0], ":")
 						if len(strList) == 1 {
 							return nil, ErrInvalidDSNAddr
 						}
@@ -122,6 +143,11 @@ func ParseDSN(dsn string) (cfg *Config, err error) {
 		return nil, ErrInvalidDSNNoSlash
 	}
 
+	// the tls param upgrades the plaintext protocol, so the connection never falls back to plaintext
+	if cfg.TLS != nil && cfg.Net == "ws" {
+		cfg.Net = "wss"
+	}
+
 	return
 }
 
@@ -159,6 +185,26 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 			if err != nil {
 				return &errors.TaosError{Code: 0xffff, ErrStr: "invalid duration value: " + value}
 			}
+		// Time Location
+		case "loc":
+			if value, err = url.QueryUnescape(value); err != nil {
+				return
+			}
+			cfg.Loc, err = time.LoadLocation(value)
+			if err != nil {
+				return
+			}
+		case "tls":
+			cfg.TLS, err = common.GetTLSConfig(value)
+			if err != nil {
+				return &errors.TaosError{Code: 0xffff, ErrStr: "invalid tls value: " + err.Error()}
+			}
+			cfg.TLSConfig = value
+		case "endpointPolicy":
+			if !endpoint.IsValidPolicy(value) {
+				return &errors.TaosError{Code: 0xffff, ErrStr: "invalid endpointPolicy value: " + value}
+			}
+			cfg.EndpointPolicy = value
 		default:
 			// lazy init
 			if cfg.Params == nil {
@@ -1,6 +1,7 @@
 package taosWS
 
 import (
+	"crypto/tls"
 	"testing"
 	"time"
 
@@ -11,6 +12,10 @@ import (
 // @date: 2023/10/13 11:26
 // @description: test parse dsn
 func TestParseDsn(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if !assert.NoError(t, err) {
+		return
+	}
 	tests := []struct {
 		name string
 		dsn  string
@@ -20,6 +25,35 @@ func TestParseDsn(t *testing.T) {
 		{name: "invalid DSN", dsn: "abcd", errs: "invalid DSN: missing the slash separating the database name"},
 		{name: "common DSN", dsn: "user:passwd@ws(fqdn:6041)/dbname", want: &Config{User: "user", Passwd: "passwd", Net: "ws", Addr: "fqdn", Port: 6041, DbName: "dbname", InterpolateParams: true}},
 		{name: "missing closing brace", dsn: "user:passwd@ws()/dbname", errs: "invalid DSN: network address not terminated (missing closing brace)"},
+		{name: "multiple endpoints", dsn: "user:passwd@ws(a:6041,b:6042,c:0)/dbname?endpointPolicy=firstAvailable", want: &Config{
+			User:              "user",
+			Passwd:            "passwd",
+			Net:               "ws",
+			Addr:              "a",
+			Port:              6041,
+			DbName:            "dbname",
+			InterpolateParams: true,
+			Endpoints:         []string{"a:6041", "b:6042", "c:0"},
+			EndpointPolicy:    "firstAvailable",
+		}},
+		{name: "multiple IPv6 endpoints", dsn: "user:passwd@ws([::1]:6041,[fe80::1]:6042)/dbname", want: &Config{
+			User:              "user",
+			Passwd:            "passwd",
+			Net:               "ws",
+			Addr:              "::1",
+			Port:              6041,
+			DbName:            "dbname",
+			InterpolateParams: true,
+			Endpoints:         []string{"[::1]:6041", "[fe80::1]:6042"},
+		}},
+		{name: "multiple endpoints without host", dsn: "user:passwd@ws(a:6041,:6042)/dbname", errs: "invalid DSN: network address not terminated (missing closing brace)"},
+		{name: "multiple endpoints wrong port", dsn: "user:passwd@ws(a:6041,b:port)/dbname", errs: "invalid DSN: network port is not a valid number"},
+		{name: "tls", dsn: "user:passwd@wss(:0)/?tls=skip-verify", want: &Config{User: "user", Passwd: "passwd", Net: "wss", InterpolateParams: true, TLSConfig: "skip-verify", TLS: &tls.Config{InsecureSkipVerify: true}}},
+		{name: "tls upgrades ws", dsn: "user:passwd@ws(:0)/?tls=skip-verify", want: &Config{User: "user", Passwd: "passwd", Net: "wss", InterpolateParams: true, TLSConfig: "skip-verify", TLS: &tls.Config{InsecureSkipVerify: true}}},
+		{name: "unregistered tls", dsn: "user:passwd@wss(:0)/?tls=unregistered", errs: "invalid tls value: tls config unregistered is not registered"},
+		{name: "loc", dsn: "user:passwd@ws(:0)/?loc=Asia%2FShanghai", want: &Config{User: "user", Passwd: "passwd", Net: "ws", InterpolateParams: true, Loc: shanghai}},
+		{name: "wrong loc", dsn: "user:passwd@ws(:0)/?loc=wrong", errs: "unknown time zone wrong"},
+		{name: "wrong endpoint policy", dsn: "user:passwd@ws(a:6041,b:6042)/dbname?endpointPolicy=wrong", errs: "invalid endpointPolicy value: wrong"},
 		{name: "default address", dsn: "user:passwd@ws(:)/dbname", want: &Config{User: "user", Passwd: "passwd", Net: "ws", DbName: "dbname", InterpolateParams: true}},
 		{name: "0 port", dsn: "user:passwd@ws(:0)/dbname", want: &Config{User: "user", Passwd: "passwd", Net: "ws", DbName: "dbname", InterpolateParams: true}},
 		{name: "wss protocol", dsn: "user:passwd@wss(:0)/", want: &Config{User: "user", Passwd: "passwd", Net: "wss", InterpolateParams: true}},
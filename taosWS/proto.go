@@ -80,41 +80,127 @@ type WSAction struct {
 	Args   json.RawMessage `json:"args"`
 }
 
+type Stmt2InitReq struct {
+	ReqID               uint64 `json:"req_id"`
+	SingleStbInsert     bool   `json:"single_stb_insert"`
+	SingleTableBindOnce bool   `json:"single_table_bind_once"`
+}
+
+type Stmt2InitResp struct {
+	BaseResp
+	StmtID uint64 `json:"stmt_id"`
+}
+
+type Stmt2PrepareReq struct {
+	ReqID     uint64 `json:"req_id"`
+	StmtID    uint64 `json:"stmt_id"`
+	SQL       string `json:"sql"`
+	GetFields bool   `json:"get_fields"`
+}
+
+type Stmt2PrepareResp struct {
+	BaseResp
+	StmtID      uint64                      `json:"stmt_id"`
+	IsInsert    bool                        `json:"is_insert"`
+	Fields      []*stmtCommon.Stmt2AllField `json:"fields"`
+	FieldsCount int                         `json:"fields_count"`
+}
+
+const (
+	Stmt2BindMessage          = 9
+	Stmt2BindProtocolVersion1 = 1
+)
+
+type Stmt2BindResp struct {
+	BaseResp
+	StmtID uint64 `json:"stmt_id"`
+}
+
+type Stmt2ExecReq struct {
+	ReqID  uint64 `json:"req_id"`
+	StmtID uint64 `json:"stmt_id"`
+}
+
+type Stmt2ExecResp struct {
+	BaseResp
+	StmtID   uint64 `json:"stmt_id"`
+	Affected int    `json:"affected"`
+}
+
+type Stmt2UseResultReq struct {
+	ReqID  uint64 `json:"req_id"`
+	StmtID uint64 `json:"stmt_id"`
+}
+
+type Stmt2UseResultResp struct {
+	BaseResp
+	StmtID           uint64   `json:"stmt_id"`
+	ResultID         uint64   `json:"result_id"`
+	FieldsCount      int      `json:"fields_count"`
+	FieldsNames      []string `json:"fields_names"`
+	FieldsTypes      []uint8  `json:"fields_types"`
+	FieldsLengths    []int64  `json:"fields_lengths"`
+	Precision        int      `json:"precision"`
+	FieldsPrecisions []int64  `json:"fields_precisions"`
+	FieldsScales     []int64  `json:"fields_scales"`
+}
+
+type Stmt2CloseReq struct {
+	ReqID  uint64 `json:"req_id"`
+	StmtID uint64 `json:"stmt_id"`
+}
+
+type Stmt2CloseResp struct {
+	BaseResp
+	StmtID uint64 `json:"stmt_id,omitempty"`
+}
+
+// The stmt protocol types below are kept for compatibility, taosWS prepares the statements with stmt2.
+
+// Deprecated: use Stmt2PrepareReq instead.
 type StmtPrepareRequest struct {
 	ReqID  uint64 `json:"req_id"`
 	StmtID uint64 `json:"stmt_id"`
 	SQL    string `json:"sql"`
 }
 
+// Deprecated: use Stmt2PrepareResp instead.
 type StmtPrepareResponse struct {
 	BaseResp
 	StmtID   uint64 `json:"stmt_id"`
 	IsInsert bool   `json:"is_insert"`
 }
 
+// Deprecated: use Stmt2InitReq instead.
 type StmtInitReq struct {
 	ReqID uint64 `json:"req_id"`
 }
 
+// Deprecated: use Stmt2InitResp instead.
 type StmtInitResp struct {
 	BaseResp
 	StmtID uint64 `json:"stmt_id"`
 }
+
+// Deprecated: use Stmt2CloseReq instead.
 type StmtCloseRequest struct {
 	ReqID  uint64 `json:"req_id"`
 	StmtID uint64 `json:"stmt_id"`
 }
 
+// Deprecated: use Stmt2CloseResp instead.
 type StmtCloseResponse struct {
 	BaseResp
 	StmtID uint64 `json:"stmt_id,omitempty"`
 }
 
+// Deprecated: the fields are returned by Stmt2PrepareResp.
 type StmtGetColFieldsRequest struct {
 	ReqID  uint64 `json:"req_id"`
 	StmtID uint64 `json:"stmt_id"`
 }
 
+// Deprecated: the fields are returned by Stmt2PrepareResp.
 type StmtGetColFieldsResponse struct {
 	BaseResp
 	StmtID uint64                  `json:"stmt_id"`
@@ -122,40 +208,48 @@ type StmtGetColFieldsResponse struct {
 }
 
 const (
+	// Deprecated: use Stmt2BindMessage instead.
 	BindMessage = 2
 )
 
+// Deprecated: use Stmt2BindResp instead.
 type StmtBindResponse struct {
 	BaseResp
 	StmtID uint64 `json:"stmt_id"`
 }
 
+// Deprecated: stmt2 binds the whole batch at once.
 type StmtAddBatchRequest struct {
 	ReqID  uint64 `json:"req_id"`
 	StmtID uint64 `json:"stmt_id"`
 }
 
+// Deprecated: stmt2 binds the whole batch at once.
 type StmtAddBatchResponse struct {
 	BaseResp
 	StmtID uint64 `json:"stmt_id"`
 }
 
+// Deprecated: use Stmt2ExecReq instead.
 type StmtExecRequest struct {
 	ReqID  uint64 `json:"req_id"`
 	StmtID uint64 `json:"stmt_id"`
 }
 
+// Deprecated: use Stmt2ExecResp instead.
 type StmtExecResponse struct {
 	BaseResp
 	StmtID   uint64 `json:"stmt_id"`
 	Affected int    `json:"affected"`
 }
 
+// Deprecated: use Stmt2UseResultReq instead.
 type StmtUseResultRequest struct {
 	ReqID  uint64 `json:"req_id"`
 	StmtID uint64 `json:"stmt_id"`
 }
 
+// Deprecated: use Stmt2UseResultResp instead.
 type StmtUseResultResponse struct {
 	BaseResp
 	StmtID        uint64   `json:"stmt_id"`
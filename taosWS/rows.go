@@ -2,9 +2,9 @@ package taosWS
 
 import (
 	"bytes"
+	"context"
 	"database/sql/driver"
 	"encoding/binary"
-	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
@@ -16,6 +16,8 @@ import (
 )
 
 type rows struct {
+	ctx              context.Context
+	freed            bool
 	buf              *bytes.Buffer
 	blockPtr         unsafe.Pointer
 	blockOffset      int
@@ -93,6 +95,7 @@ func (rs *rows) Next(dest []driver.Value) error {
 	if err != nil {
 		return err
 	}
+	common.RowTimeIn(dest, rs.conn.cfg.Loc)
 	rs.blockOffset += 1
 	return nil
 }
@@ -108,8 +111,12 @@ func (rs *rows) taosFetchBlock() error {
 	if err != nil {
 		return err
 	}
-	respBytes, err := rs.conn.readBytes()
+	respBytes, err := rs.conn.readBytes(rs.ctx, reqID)
 	if err != nil {
+		if rs.ctx.Err() != nil {
+			// the pending fetch response is dropped, release the result on the server
+			_ = rs.freeResult()
+		}
 		return err
 	}
 	if len(respBytes) < 51 {
@@ -149,24 +156,9 @@ func (rs *rows) taosFetchBlock() error {
 }
 
 func (rs *rows) freeResult() error {
-	tc := rs.conn
-	reqID := uint64(common.GetReqID())
-	req := &WSFreeResultReq{
-		ReqID: reqID,
-		ID:    rs.resultID,
-	}
-	args, err := json.Marshal(req)
-	if err != nil {
-		return err
-	}
-	action := &WSAction{
-		Action: WSFreeResult,
-		Args:   args,
-	}
-	rs.buf.Reset()
-	err = jsonI.NewEncoder(rs.buf).Encode(action)
-	if err != nil {
-		return err
+	if rs.freed {
+		return nil
 	}
-	return tc.writeText(rs.buf.Bytes())
+	rs.freed = true
+	return rs.conn.freeResult(rs.resultID)
 }
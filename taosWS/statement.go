@@ -1,7 +1,6 @@
 package taosWS
 
 import (
-	"bytes"
 	"database/sql/driver"
 	"errors"
 	"fmt"
@@ -10,36 +9,30 @@ import (
 	"time"
 
 	"github.com/taosdata/driver-go/v3/common"
-	"github.com/taosdata/driver-go/v3/common/param"
-	"github.com/taosdata/driver-go/v3/common/serializer"
 	stmtCommon "github.com/taosdata/driver-go/v3/common/stmt"
 	"github.com/taosdata/driver-go/v3/types"
 )
 
 type Stmt struct {
-	stmtID        uint64
-	conn          *taosConn
-	buffer        bytes.Buffer
-	pSql          string
-	isInsert      bool
-	cols          []*stmtCommon.StmtField
-	colTypes      *param.ColumnType
-	queryColTypes []*types.ColumnType
+	stmtID   uint64
+	conn     *taosConn
+	pSql     string
+	isInsert bool
+	fields   []*stmtCommon.Stmt2AllField
 }
 
 func (stmt *Stmt) Close() error {
 	if stmt.conn == nil || stmt.conn.isClosed() || stmt.conn.messageError != nil {
 		return driver.ErrBadConn
 	}
-	err := stmt.conn.stmtClose(stmt.stmtID)
-	stmt.buffer.Reset()
+	err := stmt.conn.stmt2Close(stmt.stmtID)
 	stmt.conn = nil
 	return err
 }
 
 func (stmt *Stmt) NumInput() int {
-	if stmt.colTypes != nil {
-		return len(stmt.cols)
+	if stmt.isInsert {
+		return len(stmt.fields)
 	}
 	return -1
 }
@@ -48,22 +41,10 @@ func (stmt *Stmt) Exec(args []driver.Value) (driver.Result, error) {
 	if stmt.conn.isClosed() {
 		return nil, driver.ErrBadConn
 	}
-	if len(args) != len(stmt.cols) {
+	if stmt.isInsert && len(args) != len(stmt.fields) {
 		return nil, fmt.Errorf("stmt exec error: wrong number of parameters")
 	}
-	block, err := serializer.SerializeRawBlock(param.NewParamsWithRowValue(args), stmt.colTypes)
-	if err != nil {
-		return nil, err
-	}
-	err = stmt.conn.stmtBindParam(stmt.stmtID, block)
-	if err != nil {
-		return nil, err
-	}
-	err = stmt.conn.stmtAddBatch(stmt.stmtID)
-	if err != nil {
-		return nil, err
-	}
-	affected, err := stmt.conn.stmtExec(stmt.stmtID)
+	affected, err := stmt.execute(args)
 	if err != nil {
 		return nil, err
 	}
@@ -74,50 +55,40 @@ func (stmt *Stmt) Query(args []driver.Value) (driver.Rows, error) {
 	if stmt.conn.isClosed() {
 		return nil, driver.ErrBadConn
 	}
-	block, err := serializer.SerializeRawBlock(param.NewParamsWithRowValue(args), param.NewColumnTypeWithValue(stmt.queryColTypes))
-	if err != nil {
-		return nil, err
-	}
-	err = stmt.conn.stmtBindParam(stmt.stmtID, block)
+	_, err := stmt.execute(args)
 	if err != nil {
 		return nil, err
 	}
-	err = stmt.conn.stmtAddBatch(stmt.stmtID)
-	if err != nil {
-		return nil, err
-	}
-	_, err = stmt.conn.stmtExec(stmt.stmtID)
-	if err != nil {
-		return nil, err
+	return stmt.conn.stmt2UseResult(stmt.stmtID)
+}
+
+func (stmt *Stmt) execute(args []driver.Value) (int, error) {
+	if len(args) > 0 {
+		bindData, err := stmtCommon.RowToStmt2BindData(stmt.isInsert, stmt.fields, args)
+		if err != nil {
+			return 0, err
+		}
+		data, err := stmtCommon.MarshalStmt2Binary([]*stmtCommon.TaosStmt2BindData{bindData}, stmt.isInsert, stmt.fields)
+		if err != nil {
+			return 0, err
+		}
+		err = stmt.conn.stmt2Bind(stmt.stmtID, data)
+		if err != nil {
+			return 0, err
+		}
 	}
-	return stmt.conn.stmtUseResult(stmt.stmtID)
+	return stmt.conn.stmt2Exec(stmt.stmtID)
 }
 
 func (stmt *Stmt) CheckNamedValue(v *driver.NamedValue) error {
 	if stmt.isInsert {
-		if stmt.cols == nil {
-			cols, err := stmt.conn.stmtGetColFields(stmt.stmtID)
-			if err != nil {
-				return err
-			}
-			colTypes := make([]*types.ColumnType, len(cols))
-			for i, col := range cols {
-				t, err := col.GetType()
-				if err != nil {
-					return err
-				}
-				colTypes[i] = t
-			}
-			stmt.cols = cols
-			stmt.colTypes = param.NewColumnTypeWithValue(colTypes)
-		}
-		if v.Ordinal > len(stmt.cols) {
+		if v.Ordinal > len(stmt.fields) {
 			return nil
 		}
 		if v.Value == nil {
 			return nil
 		}
-		switch stmt.cols[v.Ordinal-1].FieldType {
+		switch stmt.fields[v.Ordinal-1].FieldType {
 		case common.TSDB_DATA_TYPE_NULL:
 			v.Value = nil
 		case common.TSDB_DATA_TYPE_BOOL:
@@ -318,29 +289,29 @@ func (stmt *Stmt) CheckNamedValue(v *driver.NamedValue) error {
 			if is {
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 				return nil
 			}
 			rv := reflect.ValueOf(v.Value)
 			switch rv.Kind() {
 			case reflect.Float32, reflect.Float64:
-				t := common.TimestampConvertToTime(int64(rv.Float()), int(stmt.cols[v.Ordinal-1].Precision))
+				t := common.TimestampConvertToTime(int64(rv.Float()), int(stmt.fields[v.Ordinal-1].Precision))
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-				t := common.TimestampConvertToTime(rv.Int(), int(stmt.cols[v.Ordinal-1].Precision))
+				t := common.TimestampConvertToTime(rv.Int(), int(stmt.fields[v.Ordinal-1].Precision))
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				t := common.TimestampConvertToTime(int64(rv.Uint()), int(stmt.cols[v.Ordinal-1].Precision))
+				t := common.TimestampConvertToTime(int64(rv.Uint()), int(stmt.fields[v.Ordinal-1].Precision))
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 			case reflect.String:
 				t, err := time.Parse(time.RFC3339Nano, rv.String())
@@ -349,7 +320,7 @@ func (stmt *Stmt) CheckNamedValue(v *driver.NamedValue) error {
 				}
 				v.Value = types.TaosTimestamp{
 					T:         t,
-					Precision: int(stmt.cols[v.Ordinal-1].Precision),
+					Precision: int(stmt.fields[v.Ordinal-1].Precision),
 				}
 			default:
 				return fmt.Errorf("CheckNamedValue:%v can not convert to timestamp", v)
@@ -465,50 +436,27 @@ func (stmt *Stmt) CheckNamedValue(v *driver.NamedValue) error {
 	if v.Value == nil {
 		return errors.New("CheckNamedValue: value is nil")
 	}
-	if v.Ordinal == 1 {
-		stmt.queryColTypes = nil
-	}
-	if len(stmt.queryColTypes) < v.Ordinal {
-		tmp := stmt.queryColTypes
-		stmt.queryColTypes = make([]*types.ColumnType, v.Ordinal)
-		copy(stmt.queryColTypes, tmp)
-	}
 	t, is := v.Value.(time.Time)
 	if is {
 		v.Value = types.TaosBinary(t.Format(time.RFC3339Nano))
-		stmt.queryColTypes[v.Ordinal-1] = &types.ColumnType{Type: types.TaosBinaryType}
 		return nil
 	}
 	rv := reflect.ValueOf(v.Value)
 	switch rv.Kind() {
 	case reflect.Bool:
 		v.Value = types.TaosBool(rv.Bool())
-		stmt.queryColTypes[v.Ordinal-1] = &types.ColumnType{Type: types.TaosBoolType}
 	case reflect.Float32, reflect.Float64:
 		v.Value = types.TaosDouble(rv.Float())
-		stmt.queryColTypes[v.Ordinal-1] = &types.ColumnType{Type: types.TaosDoubleType}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		v.Value = types.TaosBigint(rv.Int())
-		stmt.queryColTypes[v.Ordinal-1] = &types.ColumnType{Type: types.TaosBigintType}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		v.Value = types.TaosUBigint(rv.Uint())
-		stmt.queryColTypes[v.Ordinal-1] = &types.ColumnType{Type: types.TaosUBigintType}
 	case reflect.String:
-		strVal := rv.String()
-		v.Value = types.TaosBinary(strVal)
-		stmt.queryColTypes[v.Ordinal-1] = &types.ColumnType{
-			Type:   types.TaosBinaryType,
-			MaxLen: len(strVal),
-		}
+		v.Value = types.TaosBinary(rv.String())
 	case reflect.Slice:
 		ek := rv.Type().Elem().Kind()
 		if ek == reflect.Uint8 {
-			bsVal := rv.Bytes()
-			v.Value = types.TaosBinary(bsVal)
-			stmt.queryColTypes[v.Ordinal-1] = &types.ColumnType{
-				Type:   types.TaosBinaryType,
-				MaxLen: len(bsVal),
-			}
+			v.Value = types.TaosBinary(rv.Bytes())
 		} else {
 			return fmt.Errorf("CheckNamedValue: can not convert query value %v", v)
 		}
@@ -216,6 +216,75 @@ func TestStmtQuery(t *testing.T) {
 	assert.Equal(t, 1, count)
 }
 
+func TestStmtInsertWithTags(t *testing.T) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = db.Close()
+		assert.NoError(t, err)
+	}()
+	defer func() {
+		_, err = db.Exec("drop database if exists test_ws_stmt_driver_tags")
+		assert.NoError(t, err)
+	}()
+	_, err = db.Exec("create database if not exists test_ws_stmt_driver_tags")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = db.Exec("create stable if not exists test_ws_stmt_driver_tags.stb(ts timestamp,v int) tags(t1 int,t2 nchar(20))")
+	if !assert.NoError(t, err) {
+		return
+	}
+	stmt, err := db.Prepare("insert into ? using test_ws_stmt_driver_tags.stb tags(?,?) values(?,?)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = stmt.Close()
+		assert.NoError(t, err)
+	}()
+	now := time.Now()
+	result, err := stmt.Exec("test_ws_stmt_driver_tags.ctb1", 1, "tag1", now, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	affected, err := result.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	result, err = stmt.Exec("test_ws_stmt_driver_tags.ctb2", 2, "tag2", now, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	affected, err = result.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	_, err = stmt.Exec("test_ws_stmt_driver_tags.ctb3", 3, "tag3", now)
+	assert.Error(t, err)
+
+	queryStmt, err := db.Prepare("select tbname,v,t2 from test_ws_stmt_driver_tags.stb where t1 = ? and ts = ?")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = queryStmt.Close()
+		assert.NoError(t, err)
+	}()
+	var (
+		tbName string
+		v      int32
+		t2     string
+	)
+	err = queryStmt.QueryRow(2, now).Scan(&tbName, &v, &t2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "ctb2", tbName)
+	assert.Equal(t, int32(2), v)
+	assert.Equal(t, "tag2", t2)
+}
+
 func TestStmtConvertExec(t *testing.T) {
 	db, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
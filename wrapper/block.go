@@ -47,3 +47,36 @@ func TaosWriteRawBlockWithFieldsWithReqID(conn unsafe.Pointer, numOfRows int, pD
 	defer C.free(unsafe.Pointer(cStr))
 	return int(C.taos_write_raw_block_with_fields_with_reqid(conn, (C.int)(numOfRows), (*C.char)(pData), cStr, (*C.struct_taosField)(fields), (C.int)(numFields), (C.int64_t)(reqID)))
 }
+
+// RawBlockField describes a column of the raw block written by TaosWriteRawBlockWithFields.
+type RawBlockField struct {
+	Name  string
+	Type  uint8
+	Bytes int32
+}
+
+// NewTaosFields allocates a TAOS_FIELD array for the fields, the array must be released by FreeTaosFields.
+func NewTaosFields(fields []*RawBlockField) unsafe.Pointer {
+	if len(fields) == 0 {
+		return nil
+	}
+	p := C.calloc(C.size_t(len(fields)), C.size_t(C.sizeof_struct_taosField))
+	for i, field := range fields {
+		f := (*C.struct_taosField)(unsafe.Pointer(uintptr(p) + uintptr(C.sizeof_struct_taosField*C.int(i))))
+		name := field.Name
+		if len(name) > len(f.name)-1 {
+			name = name[:len(f.name)-1]
+		}
+		for j := 0; j < len(name); j++ {
+			f.name[j] = C.char(name[j])
+		}
+		f._type = C.int8_t(field.Type)
+		f.bytes = C.int32_t(field.Bytes)
+	}
+	return p
+}
+
+// FreeTaosFields releases the array allocated by NewTaosFields.
+func FreeTaosFields(fields unsafe.Pointer) {
+	C.free(fields)
+}
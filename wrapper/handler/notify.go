@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/errors"
+	"github.com/taosdata/driver-go/v3/wrapper"
+	"github.com/taosdata/driver-go/v3/wrapper/cgo"
+	"github.com/taosdata/driver-go/v3/wrapper/thread"
+)
+
+// NotifyTypes are the notify types registered for a Notifier.
+var NotifyTypes = []int{common.TAOS_NOTIFY_PASSVER, common.TAOS_NOTIFY_WHITELIST_VER, common.TAOS_NOTIFY_USER_DROPPED}
+
+const notifyBufferSize = 8
+
+// Notifier receives the notifications of a connection by the handles registered with wrapper.TaosSetNotifyCB
+// and delivers them to the handler in order.
+type Notifier struct {
+	passVer      chan int32
+	whitelistVer chan int64
+	userDropped  chan struct{}
+	handles      map[int]cgo.Handle
+	lock         sync.RWMutex
+	handler      func(common.NotifyEvent)
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+func NewNotifier() *Notifier {
+	n := &Notifier{
+		passVer:      make(chan int32, notifyBufferSize),
+		whitelistVer: make(chan int64, notifyBufferSize),
+		userDropped:  make(chan struct{}, notifyBufferSize),
+		done:         make(chan struct{}),
+	}
+	n.handles = map[int]cgo.Handle{
+		common.TAOS_NOTIFY_PASSVER:       cgo.NewHandle(n.passVer),
+		common.TAOS_NOTIFY_WHITELIST_VER: cgo.NewHandle(n.whitelistVer),
+		common.TAOS_NOTIFY_USER_DROPPED:  cgo.NewHandle(n.userDropped),
+	}
+	go n.run()
+	return n
+}
+
+// RegisterNotifier creates a Notifier and registers its handles for NotifyTypes on the connection,
+// the Notifier is closed when the registration fails.
+func RegisterNotifier(taos unsafe.Pointer, locker *thread.Locker) (*Notifier, error) {
+	notifier := NewNotifier()
+	for _, notifyType := range NotifyTypes {
+		locker.Lock()
+		code := wrapper.TaosSetNotifyCB(taos, notifier.Handle(notifyType), notifyType)
+		locker.Unlock()
+		if code != 0 {
+			notifier.Close()
+			return nil, errors.NewError(int(code), wrapper.TaosErrorStr(nil))
+		}
+	}
+	return notifier, nil
+}
+
+// Handle returns the handle to register with wrapper.TaosSetNotifyCB for the notify type.
+func (n *Notifier) Handle(notifyType int) cgo.Handle {
+	return n.handles[notifyType]
+}
+
+// SetHandler sets the handler of the notifications, the notifications are dropped when the handler is nil.
+func (n *Notifier) SetHandler(handler func(common.NotifyEvent)) {
+	n.lock.Lock()
+	n.handler = handler
+	n.lock.Unlock()
+}
+
+func (n *Notifier) run() {
+	for {
+		var event common.NotifyEvent
+		select {
+		case <-n.done:
+			return
+		case version := <-n.passVer:
+			event = common.NotifyEvent{Type: common.NotifyPasswordChanged, Version: int64(version)}
+		case version := <-n.whitelistVer:
+			event = common.NotifyEvent{Type: common.NotifyWhitelistChanged, Version: version}
+		case <-n.userDropped:
+			event = common.NotifyEvent{Type: common.NotifyUserDropped}
+		}
+		n.lock.RLock()
+		handler := n.handler
+		n.lock.RUnlock()
+		if handler != nil {
+			handler(event)
+		}
+	}
+}
+
+// Close stops delivering the notifications and releases the handles, it must be called after the connection is closed.
+// Close does not wait for the running handler, so it can be called inside the handler.
+func (n *Notifier) Close() {
+	n.closeOnce.Do(func() {
+		close(n.done)
+		for _, h := range n.handles {
+			h.Delete()
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taosdata/driver-go/v3/common"
+)
+
+func TestNotifier(t *testing.T) {
+	n := NewNotifier()
+	events := make(chan common.NotifyEvent, 3)
+	n.SetHandler(func(event common.NotifyEvent) {
+		events <- event
+	})
+	n.Handle(common.TAOS_NOTIFY_PASSVER).Value().(chan int32) <- 2
+	n.Handle(common.TAOS_NOTIFY_WHITELIST_VER).Value().(chan int64) <- 3
+	n.Handle(common.TAOS_NOTIFY_USER_DROPPED).Value().(chan struct{}) <- struct{}{}
+	var got []common.NotifyEvent
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-events:
+			got = append(got, event)
+		case <-time.After(time.Second * 5):
+			t.Fatal("wait for notify event timeout")
+		}
+	}
+	assert.ElementsMatch(t, []common.NotifyEvent{
+		{Type: common.NotifyPasswordChanged, Version: 2},
+		{Type: common.NotifyWhitelistChanged, Version: 3},
+		{Type: common.NotifyUserDropped},
+	}, got)
+	handle := n.Handle(common.TAOS_NOTIFY_PASSVER)
+	n.Close()
+	n.Close()
+	assert.Panics(t, func() {
+		handle.Value()
+	})
+}
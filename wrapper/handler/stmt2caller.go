@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"unsafe"
+
+	"github.com/taosdata/driver-go/v3/wrapper/cgo"
+)
+
+type Stmt2Result struct {
+	Res      unsafe.Pointer
+	Affected int
+	N        int
+}
+
+type Stmt2CallBackCaller struct {
+	ExecResult chan *Stmt2Result
+}
+
+type Stmt2CallBackCallerPool struct {
+	pool chan cgo.Handle
+}
+
+const Stmt2CBPoolSize = 10000
+
+func NewStmt2CallBackCallerPool(size int) *Stmt2CallBackCallerPool {
+	return &Stmt2CallBackCallerPool{
+		pool: make(chan cgo.Handle, size),
+	}
+}
+
+func (p *Stmt2CallBackCallerPool) Get() (cgo.Handle, *Stmt2CallBackCaller) {
+	select {
+	case h := <-p.pool:
+		return h, h.Value().(*Stmt2CallBackCaller)
+	default:
+		c := &Stmt2CallBackCaller{
+			ExecResult: make(chan *Stmt2Result, 1),
+		}
+		return cgo.NewHandle(c), c
+	}
+}
+
+func (p *Stmt2CallBackCallerPool) Put(h cgo.Handle) {
+	select {
+	case p.pool <- h:
+	default:
+		h.Delete()
+	}
+}
+
+func (s *Stmt2CallBackCaller) ExecCall(res unsafe.Pointer, affected int, code int) {
+	s.ExecResult <- &Stmt2Result{
+		Res:      res,
+		Affected: affected,
+		N:        code,
+	}
+}
+
+// GlobalStmt2CallBackCallerPool is the stmt2 callback caller pool shared by af and taosSql
+var GlobalStmt2CallBackCallerPool = NewStmt2CallBackCallerPool(Stmt2CBPoolSize)
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStmt2CallBackCaller(t *testing.T) {
+	pool := NewStmt2CallBackCallerPool(1)
+	handle, caller := pool.Get()
+	assert.Same(t, caller, handle.Value().(*Stmt2CallBackCaller))
+	caller.ExecCall(nil, 3, 0)
+	result := <-caller.ExecResult
+	assert.Equal(t, &Stmt2Result{Affected: 3}, result)
+	pool.Put(handle)
+	handle2, caller2 := pool.Get()
+	assert.Equal(t, handle, handle2)
+	assert.Same(t, caller, caller2)
+	handle3, _ := pool.Get()
+	assert.NotEqual(t, handle, handle3)
+	pool.Put(handle2)
+	pool.Put(handle3)
+	assert.Panics(t, func() {
+		handle3.Value()
+	})
+}
@@ -24,13 +24,57 @@ func NotifyCallback(p unsafe.Pointer, ext unsafe.Pointer, notifyType C.int) {
 	case common.TAOS_NOTIFY_PASSVER:
 		version := int32(*(*C.int32_t)(ext))
 		c := (*(*cgo.Handle)(p)).Value().(chan int32)
-		c <- version
+		notifyPassVer(c, version)
 	case common.TAOS_NOTIFY_WHITELIST_VER:
 		version := int64(*(*C.int64_t)(ext))
 		c := (*(*cgo.Handle)(p)).Value().(chan int64)
-		c <- version
+		notifyWhitelistVer(c, version)
 	case common.TAOS_NOTIFY_USER_DROPPED:
 		c := (*(*cgo.Handle)(p)).Value().(chan struct{})
-		c <- struct{}{}
+		notifyUserDropped(c)
+	}
+}
+
+// notifyPassVer sends the version without blocking the taosc callback thread, when the channel is full
+// the oldest version is dropped once to keep the latest one, the version is dropped if it still can not be sent.
+func notifyPassVer(c chan int32, version int32) {
+	select {
+	case c <- version:
+		return
+	default:
+	}
+	select {
+	case <-c:
+	default:
+	}
+	select {
+	case c <- version:
+	default:
+	}
+}
+
+// notifyWhitelistVer sends the version without blocking the taosc callback thread, when the channel is full
+// the oldest version is dropped once to keep the latest one, the version is dropped if it still can not be sent.
+func notifyWhitelistVer(c chan int64, version int64) {
+	select {
+	case c <- version:
+		return
+	default:
+	}
+	select {
+	case <-c:
+	default:
+	}
+	select {
+	case c <- version:
+	default:
+	}
+}
+
+// notifyUserDropped drops the event when the channel is full since a user dropped event is already pending
+func notifyUserDropped(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
 	}
 }
@@ -0,0 +1,42 @@
+package wrapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifyNonBlocking(t *testing.T) {
+	passVer := make(chan int32, 2)
+	for i := int32(1); i <= 5; i++ {
+		notifyPassVer(passVer, i)
+	}
+	assert.Equal(t, 2, len(passVer))
+	assert.Equal(t, int32(4), <-passVer)
+	assert.Equal(t, int32(5), <-passVer)
+
+	whitelistVer := make(chan int64, 1)
+	notifyWhitelistVer(whitelistVer, 1)
+	notifyWhitelistVer(whitelistVer, 2)
+	assert.Equal(t, int64(2), <-whitelistVer)
+
+	userDropped := make(chan struct{}, 1)
+	notifyUserDropped(userDropped)
+	notifyUserDropped(userDropped)
+	assert.Equal(t, 1, len(userDropped))
+
+	// an unbuffered channel without reader drops the event instead of spinning
+	done := make(chan struct{})
+	go func() {
+		notifyPassVer(make(chan int32), 1)
+		notifyWhitelistVer(make(chan int64), 1)
+		notifyUserDropped(make(chan struct{}))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("notify blocked on unbuffered channel")
+	}
+}
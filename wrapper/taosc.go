@@ -15,6 +15,9 @@ extern void FetchRawBlockCallback(void *param,TAOS_RES *,int numOfRows);
 int taos_options_wrapper(TSDB_OPTION option, char *arg) {
 	return taos_options(option,arg);
 };
+int taos_options_connection_wrapper(TAOS *taos, TSDB_OPTION_CONNECTION option, char *arg) {
+	return taos_options_connection(taos,option,arg);
+};
 void taos_fetch_rows_a_wrapper(TAOS_RES *res, void *param){
 	return taos_fetch_rows_a(res,FetchRowsCallback,param);
 };
@@ -89,6 +92,16 @@ func TaosQueryWithReqID(taosConn unsafe.Pointer, sql string, reqID int64) unsafe
 	return unsafe.Pointer(C.taos_query_with_reqid(taosConn, cSql, (C.int64_t)(reqID)))
 }
 
+// TaosKillQuery void taos_kill_query(TAOS *taos);
+func TaosKillQuery(taosConnect unsafe.Pointer) {
+	C.taos_kill_query(taosConnect)
+}
+
+// TaosStopQuery void taos_stop_query(TAOS_RES *res);
+func TaosStopQuery(result unsafe.Pointer) {
+	C.taos_stop_query(result)
+}
+
 // TaosError int taos_errno(TAOS_RES *tres);
 func TaosError(result unsafe.Pointer) int {
 	return int(C.taos_errno(result))
@@ -156,6 +169,17 @@ func TaosOptions(option int, value string) int {
 	return int(C.taos_options_wrapper((C.TSDB_OPTION)(option), cValue))
 }
 
+// TaosOptionsConnection int taos_options_connection(TAOS *taos, TSDB_OPTION_CONNECTION option, const void *arg, ...);
+// The option is reset to the default value when value is nil.
+func TaosOptionsConnection(taosConnect unsafe.Pointer, option int, value *string) int {
+	var cValue *C.char
+	if value != nil {
+		cValue = C.CString(*value)
+		defer C.free(unsafe.Pointer(cValue))
+	}
+	return int(C.taos_options_connection_wrapper(taosConnect, (C.TSDB_OPTION_CONNECTION)(option), cValue))
+}
+
 // TaosQueryA void taos_query_a(TAOS *taos, const char *sql, void (*fp)(void *param, TAOS_RES *, int code), void *param);
 func TaosQueryA(taosConnect unsafe.Pointer, sql string, caller cgo.Handle) {
 	cSql := C.CString(sql)
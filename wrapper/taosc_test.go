@@ -606,3 +606,50 @@ func TestTaosGetServerInfo(t *testing.T) {
 	info := TaosGetServerInfo(conn)
 	assert.NotEmpty(t, info)
 }
+
+func TestTaosKillQuery(t *testing.T) {
+	conn, err := TaosConnect("", "root", "taosdata", "", 0)
+	assert.NoError(t, err)
+	defer TaosClose(conn)
+	caller := NewTestCaller()
+	p := cgo.NewHandle(caller)
+	defer p.Delete()
+	TaosQueryA(conn, "select server_version()", p)
+	TaosKillQuery(conn)
+	r := <-caller.QueryResult
+	TaosFreeResult(r.res)
+}
+
+func TestTaosStopQuery(t *testing.T) {
+	conn, err := TaosConnect("", "root", "taosdata", "", 0)
+	assert.NoError(t, err)
+	defer TaosClose(conn)
+	res := TaosQuery(conn, "select server_version()")
+	code := TaosError(res)
+	if !assert.Equal(t, 0, code, TaosErrorStr(res)) {
+		TaosFreeResult(res)
+		return
+	}
+	TaosStopQuery(res)
+	TaosFreeResult(res)
+}
+
+func TestTaosOptionsConnection(t *testing.T) {
+	conn, err := TaosConnect("", "root", "taosdata", "", 0)
+	assert.NoError(t, err)
+	defer TaosClose(conn)
+	timezone := "Asia/Shanghai"
+	code := TaosOptionsConnection(conn, common.TSDB_OPTION_CONNECTION_TIMEZONE, &timezone)
+	if code != 0 {
+		t.Errorf("TaosOptionsConnection() error code= %d, msg: %s", code, TaosErrorStr(nil))
+	}
+	app := "test_app"
+	code = TaosOptionsConnection(conn, common.TSDB_OPTION_CONNECTION_USER_APP, &app)
+	if code != 0 {
+		t.Errorf("TaosOptionsConnection() error code= %d, msg: %s", code, TaosErrorStr(nil))
+	}
+	code = TaosOptionsConnection(conn, common.TSDB_OPTION_CONNECTION_CLEAR, nil)
+	if code != 0 {
+		t.Errorf("TaosOptionsConnection() error code= %d, msg: %s", code, TaosErrorStr(nil))
+	}
+}
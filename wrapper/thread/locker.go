@@ -1,19 +1,120 @@
 package thread
 
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Locker limits the number of the concurrent cgo calls, the waiters are served in order.
+// The size can be changed at any time and the statistics are reported by Stats.
 type Locker struct {
-	c chan struct{}
+	lock        sync.Mutex
+	size        int
+	holders     int
+	waiters     list.List
+	acquired    uint64
+	waited      uint64
+	waitTime    time.Duration
+	maxWaitTime time.Duration
 }
 
-var nothing = struct{}{}
+// Stats is the statistics of Locker.
+type Stats struct {
+	// Size is the max number of the holders
+	Size int
+	// Holders is the number of the current holders
+	Holders int
+	// Waiters is the number of the callers waiting for the lock
+	Waiters int
+	// Acquired is the total number of the acquired locks
+	Acquired uint64
+	// Waited is the number of the acquired locks which had to wait
+	Waited uint64
+	// WaitTime is the total wait time
+	WaitTime time.Duration
+	// MaxWaitTime is the longest wait time
+	MaxWaitTime time.Duration
+}
 
 func NewLocker(count int) *Locker {
-	return &Locker{c: make(chan struct{}, count)}
+	if count < 1 {
+		count = 1
+	}
+	return &Locker{size: count}
 }
 
 func (l *Locker) Lock() {
-	l.c <- nothing
+	l.lock.Lock()
+	if l.holders < l.size && l.waiters.Len() == 0 {
+		l.holders += 1
+		l.acquired += 1
+		l.lock.Unlock()
+		return
+	}
+	ready := make(chan struct{})
+	l.waiters.PushBack(ready)
+	l.lock.Unlock()
+	start := time.Now()
+	<-ready
+	wait := time.Since(start)
+	l.lock.Lock()
+	l.waited += 1
+	l.waitTime += wait
+	if wait > l.maxWaitTime {
+		l.maxWaitTime = wait
+	}
+	l.lock.Unlock()
 }
 
 func (l *Locker) Unlock() {
-	<-l.c
+	l.lock.Lock()
+	l.holders -= 1
+	l.wakeup()
+	l.lock.Unlock()
+}
+
+// SetSize Change the max number of the holders, the current holders are not affected
+// and the waiters are woken up when the size grows
+func (l *Locker) SetSize(count int) {
+	if count < 1 {
+		count = 1
+	}
+	l.lock.Lock()
+	l.size = count
+	l.wakeup()
+	l.lock.Unlock()
+}
+
+// Size Return the max number of the holders
+func (l *Locker) Size() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.size
+}
+
+// Stats Return the statistics of the locker
+func (l *Locker) Stats() Stats {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return Stats{
+		Size:        l.size,
+		Holders:     l.holders,
+		Waiters:     l.waiters.Len(),
+		Acquired:    l.acquired,
+		Waited:      l.waited,
+		WaitTime:    l.waitTime,
+		MaxWaitTime: l.maxWaitTime,
+	}
+}
+
+// wakeup hands the lock to the waiters in order, the lock of the locker must be held
+func (l *Locker) wakeup() {
+	for l.holders < l.size && l.waiters.Len() != 0 {
+		e := l.waiters.Front()
+		l.waiters.Remove(e)
+		l.holders += 1
+		l.acquired += 1
+		close(e.Value.(chan struct{}))
+	}
 }
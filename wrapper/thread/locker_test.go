@@ -2,6 +2,9 @@ package thread
 
 import (
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // @author: xftan
@@ -30,3 +33,73 @@ func TestNewLocker(t *testing.T) {
 		})
 	}
 }
+
+func TestLockerSetSize(t *testing.T) {
+	locker := NewLocker(1)
+	locker.Lock()
+	acquired := make(chan struct{})
+	go func() {
+		locker.Lock()
+		close(acquired)
+	}()
+	for locker.Stats().Waiters != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	locker.SetSize(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("waiter is not woken up after resize")
+	}
+	stats := locker.Stats()
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, 2, stats.Holders)
+	assert.Equal(t, 0, stats.Waiters)
+	locker.Unlock()
+	locker.Unlock()
+	locker.SetSize(0)
+	assert.Equal(t, 1, locker.Size())
+}
+
+func TestLockerStats(t *testing.T) {
+	locker := NewLocker(1)
+	locker.Lock()
+	done := make(chan struct{})
+	go func() {
+		locker.Lock()
+		locker.Unlock()
+		close(done)
+	}()
+	for locker.Stats().Waiters != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(time.Millisecond * 10)
+	locker.Unlock()
+	<-done
+	stats := locker.Stats()
+	assert.Equal(t, 0, stats.Holders)
+	assert.Equal(t, uint64(2), stats.Acquired)
+	assert.Equal(t, uint64(1), stats.Waited)
+	assert.True(t, stats.WaitTime >= time.Millisecond*10)
+	assert.Equal(t, stats.WaitTime, stats.MaxWaitTime)
+}
+
+func TestLockerOrder(t *testing.T) {
+	locker := NewLocker(1)
+	locker.Lock()
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			locker.Lock()
+			order <- i
+			locker.Unlock()
+		}(i)
+		for locker.Stats().Waiters != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	locker.Unlock()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, i, <-order)
+	}
+}
@@ -22,6 +22,8 @@ type Config struct {
 	autoReconnect       bool
 	reconnectIntervalMs int
 	reconnectRetryCount int
+	endpointPolicy      string
+	tlsConfig           string
 }
 
 func NewConfig(url string, chanLength uint, opts ...func(*Config)) *Config {
@@ -92,3 +94,18 @@ func SetReconnectRetryCount(reconnectRetryCount int) func(*Config) {
 		c.reconnectRetryCount = reconnectRetryCount
 	}
 }
+
+// SetEndpointPolicy sets the policy to choose the host when the url contains comma separated hosts,
+// one of roundRobin, random and firstAvailable.
+func SetEndpointPolicy(policy string) func(*Config) {
+	return func(c *Config) {
+		c.endpointPolicy = policy
+	}
+}
+
+// SetTLSConfig uses the tls.Config registered by common.RegisterTLSConfig with the name.
+func SetTLSConfig(name string) func(*Config) {
+	return func(c *Config) {
+		c.tlsConfig = name
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/gorilla/websocket"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 	"github.com/taosdata/driver-go/v3/ws/client"
 )
 
@@ -25,7 +26,7 @@ const (
 type Schemaless struct {
 	client              *client.Client
 	sendList            *list.List
-	url                 string
+	balancer            *endpoint.Balancer
 	user                string
 	password            string
 	db                  string
@@ -53,7 +54,15 @@ func NewSchemaless(config *Config) (*Schemaless, error) {
 	wsUrl.Path = "/ws"
 	dialer := common.DefaultDialer
 	dialer.EnableCompression = config.enableCompression
-	conn, _, err := dialer.Dial(wsUrl.String(), nil)
+	dialer.TLSClientConfig, err = common.GetTLSConfig(config.tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("config tls error: %s", err)
+	}
+	balancer, err := endpoint.NewBalancer(endpoint.SplitURL(wsUrl), config.endpointPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("config url error: %s", err)
+	}
+	conn, err := endpoint.Dial(&dialer, balancer)
 	if err != nil {
 		return nil, fmt.Errorf("dial ws error: %s", err)
 	}
@@ -61,7 +70,7 @@ func NewSchemaless(config *Config) (*Schemaless, error) {
 	s := Schemaless{
 		client:       client.NewClient(conn, config.chanLength),
 		sendList:     list.New(),
-		url:          wsUrl.String(),
+		balancer:     balancer,
 		user:         config.user,
 		password:     config.password,
 		db:           config.db,
@@ -108,7 +117,7 @@ func (s *Schemaless) reconnect() error {
 	reconnected := false
 	for i := 0; i < s.reconnectRetryCount; i++ {
 		time.Sleep(time.Duration(s.reconnectIntervalMs) * time.Millisecond)
-		conn, _, err := s.dialer.Dial(s.url, nil)
+		conn, err := endpoint.Dial(s.dialer, s.balancer)
 		if err != nil {
 			continue
 		}
@@ -270,4 +270,12 @@ func TestWrongNewSchemaless(t *testing.T) {
 	))
 	assert.Error(t, err)
 	assert.Nil(t, s)
+
+	s, err = NewSchemaless(NewConfig("wss://localhost:6041", 1,
+		SetUser("root"),
+		SetPassword("taosdata"),
+		SetTLSConfig("unregistered"),
+	))
+	assert.Error(t, err)
+	assert.Nil(t, s)
 }
@@ -3,6 +3,8 @@ package stmt
 import (
 	"errors"
 	"time"
+
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 )
 
 type Config struct {
@@ -19,6 +21,8 @@ type Config struct {
 	AutoReconnect       bool
 	ReconnectIntervalMs int
 	ReconnectRetryCount int
+	EndpointPolicy      string
+	TLSConfig           string // name of the tls.Config registered by common.RegisterTLSConfig, resolved by NewConnector
 }
 
 func NewConfig(url string, chanLength uint) *Config {
@@ -82,3 +86,19 @@ func (c *Config) SetReconnectIntervalMs(reconnectIntervalMs int) {
 func (c *Config) SetReconnectRetryCount(reconnectRetryCount int) {
 	c.ReconnectRetryCount = reconnectRetryCount
 }
+
+// SetEndpointPolicy sets the policy to choose the host when the url contains comma separated hosts,
+// one of roundRobin, random and firstAvailable.
+func (c *Config) SetEndpointPolicy(policy string) error {
+	if !endpoint.IsValidPolicy(policy) {
+		return errors.New("invalid endpoint policy: " + policy)
+	}
+	c.EndpointPolicy = policy
+	return nil
+}
+
+// SetTLSConfig uses the tls.Config registered by common.RegisterTLSConfig with the name,
+// the name is resolved by NewConnector.
+func (c *Config) SetTLSConfig(name string) {
+	c.TLSConfig = name
+}
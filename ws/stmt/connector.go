@@ -10,6 +10,7 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 	"github.com/taosdata/driver-go/v3/ws/client"
 )
 
@@ -20,7 +21,7 @@ type Connector struct {
 	config              *Config
 	customErrorHandler  func(*Connector, error)
 	customCloseHandler  func()
-	url                 string
+	balancer            *endpoint.Balancer
 	chanLength          uint
 	dialer              *websocket.Dialer
 	autoReconnect       bool
@@ -51,12 +52,21 @@ func NewConnector(config *Config) (*Connector, error) {
 	}
 	dialer := common.DefaultDialer
 	dialer.EnableCompression = config.EnableCompression
+	tlsConfig, err := common.GetTLSConfig(config.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	dialer.TLSClientConfig = tlsConfig
 	u, err := url.Parse(config.Url)
 	if err != nil {
 		return nil, err
 	}
 	u.Path = "/ws"
-	ws, _, err := dialer.Dial(u.String(), nil)
+	balancer, err := endpoint.NewBalancer(endpoint.SplitURL(u), config.EndpointPolicy)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := endpoint.Dial(&dialer, balancer)
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +92,7 @@ func NewConnector(config *Config) (*Connector, error) {
 		config:              config,
 		customErrorHandler:  config.ErrorHandler,
 		customCloseHandler:  config.CloseHandler,
-		url:                 u.String(),
+		balancer:            balancer,
 		dialer:              &dialer,
 		chanLength:          config.ChanLength,
 		autoReconnect:       config.AutoReconnect,
@@ -158,7 +168,7 @@ func (c *Connector) reconnect() error {
 	reconnected := false
 	for i := 0; i < c.reconnectRetryCount; i++ {
 		time.Sleep(time.Duration(c.reconnectIntervalMs) * time.Millisecond)
-		conn, _, err := c.dialer.Dial(c.url, nil)
+		conn, err := endpoint.Dial(c.dialer, c.balancer)
 		if err != nil {
 			continue
 		}
@@ -212,25 +222,9 @@ func (c *Connector) Init() (*Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
-	respBytes, err := c.client.sendText(reqID, envelope)
+	respBytes, err := c.sendTextWithReconnect(reqID, envelope)
 	if err != nil {
-		if !c.autoReconnect {
-			return nil, err
-		}
-
-		var opError *net.OpError
-		if !c.client.client.IsRunning() || errors.Is(err, client.ClosedError) || errors.As(err, &opError) {
-			err = c.reconnect()
-			if err != nil {
-				return nil, err
-			}
-			respBytes, err = c.client.sendText(reqID, envelope)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, err
-		}
+		return nil, err
 	}
 	var resp InitResp
 	err = client.JsonI.Unmarshal(respBytes, &resp)
@@ -245,6 +239,69 @@ func (c *Connector) Init() (*Stmt, error) {
 	return s, nil
 }
 
+// InitStmt2 init stmt2 over websocket
+func (c *Connector) InitStmt2(singleStbInsert bool, singleTableBindOnce bool) (*Stmt2, error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.closed {
+		return nil, ErrConnIsClosed
+	}
+	reqID := c.generateReqID()
+	req := &Stmt2InitReq{
+		ReqID:               reqID,
+		SingleStbInsert:     singleStbInsert,
+		SingleTableBindOnce: singleTableBindOnce,
+	}
+	args, err := client.JsonI.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	action := &client.WSAction{
+		Action: STMT2Init,
+		Args:   args,
+	}
+	envelope := client.GlobalEnvelopePool.Get()
+	defer client.GlobalEnvelopePool.Put(envelope)
+	err = client.JsonI.NewEncoder(envelope.Msg).Encode(action)
+	if err != nil {
+		return nil, err
+	}
+	respBytes, err := c.sendTextWithReconnect(reqID, envelope)
+	if err != nil {
+		return nil, err
+	}
+	var resp Stmt2InitResp
+	err = client.JsonI.Unmarshal(respBytes, &resp)
+	err = client.HandleResponseError(err, resp.Code, resp.Message)
+	if err != nil {
+		return nil, err
+	}
+	s := &Stmt2{
+		id:        resp.StmtID,
+		connector: c.client,
+	}
+	return s, nil
+}
+
+func (c *Connector) sendTextWithReconnect(reqID uint64, envelope *client.Envelope) ([]byte, error) {
+	respBytes, err := c.client.sendText(reqID, envelope)
+	if err == nil {
+		return respBytes, nil
+	}
+	if !c.autoReconnect {
+		return nil, err
+	}
+	var opError *net.OpError
+	if !c.client.client.IsRunning() || errors.Is(err, client.ClosedError) || errors.As(err, &opError) {
+		err = c.reconnect()
+		if err != nil {
+			return nil, err
+		}
+		return c.client.sendText(reqID, envelope)
+	}
+	return nil, err
+}
+
 func (c *Connector) Close() error {
 	c.Lock()
 	defer c.Unlock()
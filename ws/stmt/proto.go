@@ -1,6 +1,10 @@
 package stmt
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	stmtCommon "github.com/taosdata/driver-go/v3/common/stmt"
+)
 
 const (
 	SetTagsMessage = 1
@@ -187,3 +191,110 @@ type WSFreeResultRequest struct {
 	ReqID uint64 `json:"req_id"`
 	ID    uint64 `json:"id"`
 }
+
+const (
+	Stmt2BindMessage          = 9
+	Stmt2BindProtocolVersion1 = 1
+)
+
+const (
+	STMT2Init    = "stmt2_init"
+	STMT2Prepare = "stmt2_prepare"
+	STMT2Exec    = "stmt2_exec"
+	STMT2Result  = "stmt2_result"
+	STMT2Close   = "stmt2_close"
+)
+
+type Stmt2InitReq struct {
+	ReqID               uint64 `json:"req_id"`
+	SingleStbInsert     bool   `json:"single_stb_insert"`
+	SingleTableBindOnce bool   `json:"single_table_bind_once"`
+}
+
+type Stmt2InitResp struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Action  string `json:"action"`
+	ReqID   uint64 `json:"req_id"`
+	Timing  int64  `json:"timing"`
+	StmtID  uint64 `json:"stmt_id"`
+}
+
+type Stmt2PrepareReq struct {
+	ReqID     uint64 `json:"req_id"`
+	StmtID    uint64 `json:"stmt_id"`
+	SQL       string `json:"sql"`
+	GetFields bool   `json:"get_fields"`
+}
+
+type Stmt2PrepareResp struct {
+	Code        int                         `json:"code"`
+	Message     string                      `json:"message"`
+	Action      string                      `json:"action"`
+	ReqID       uint64                      `json:"req_id"`
+	Timing      int64                       `json:"timing"`
+	StmtID      uint64                      `json:"stmt_id"`
+	IsInsert    bool                        `json:"is_insert"`
+	Fields      []*stmtCommon.Stmt2AllField `json:"fields"`
+	FieldsCount int                         `json:"fields_count"`
+}
+
+type Stmt2BindResp struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Action  string `json:"action"`
+	ReqID   uint64 `json:"req_id"`
+	Timing  int64  `json:"timing"`
+	StmtID  uint64 `json:"stmt_id"`
+}
+
+type Stmt2ExecReq struct {
+	ReqID  uint64 `json:"req_id"`
+	StmtID uint64 `json:"stmt_id"`
+}
+
+type Stmt2ExecResp struct {
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	Action   string `json:"action"`
+	ReqID    uint64 `json:"req_id"`
+	Timing   int64  `json:"timing"`
+	StmtID   uint64 `json:"stmt_id"`
+	Affected int    `json:"affected"`
+}
+
+type Stmt2UseResultReq struct {
+	ReqID  uint64 `json:"req_id"`
+	StmtID uint64 `json:"stmt_id"`
+}
+
+type Stmt2UseResultResp struct {
+	Code             int      `json:"code"`
+	Message          string   `json:"message"`
+	Action           string   `json:"action"`
+	ReqID            uint64   `json:"req_id"`
+	Timing           int64    `json:"timing"`
+	StmtID           uint64   `json:"stmt_id"`
+	ResultID         uint64   `json:"result_id"`
+	FieldsCount      int      `json:"fields_count"`
+	FieldsNames      []string `json:"fields_names"`
+	FieldsTypes      []uint8  `json:"fields_types"`
+	FieldsLengths    []int64  `json:"fields_lengths"`
+	Precision        int      `json:"precision"`
+	FieldsPrecisions []int64  `json:"fields_precisions"`
+	FieldsScales     []int64  `json:"fields_scales"`
+}
+
+type Stmt2CloseReq struct {
+	ReqID  uint64 `json:"req_id"`
+	StmtID uint64 `json:"stmt_id"`
+}
+
+type Stmt2CloseResp struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Action  string `json:"action"`
+	ReqID   uint64 `json:"req_id"`
+	Timing  int64  `json:"timing"`
+	StmtID  uint64 `json:"stmt_id"`
+}
@@ -0,0 +1,220 @@
+package stmt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	stmtCommon "github.com/taosdata/driver-go/v3/common/stmt"
+	"github.com/taosdata/driver-go/v3/ws/client"
+)
+
+type Stmt2 struct {
+	connector    *WSConn
+	id           uint64
+	isInsert     *bool
+	fields       []*stmtCommon.Stmt2AllField
+	lastAffected int
+}
+
+func (s *Stmt2) Prepare(sql string) error {
+	reqID := s.connector.generateReqID()
+	req := &Stmt2PrepareReq{
+		ReqID:     reqID,
+		StmtID:    s.id,
+		SQL:       sql,
+		GetFields: true,
+	}
+	args, err := client.JsonI.Marshal(req)
+	if err != nil {
+		return err
+	}
+	action := &client.WSAction{
+		Action: STMT2Prepare,
+		Args:   args,
+	}
+	envelope := client.GlobalEnvelopePool.Get()
+	defer client.GlobalEnvelopePool.Put(envelope)
+	err = client.JsonI.NewEncoder(envelope.Msg).Encode(action)
+	if err != nil {
+		return err
+	}
+	respBytes, err := s.connector.sendText(reqID, envelope)
+	if err != nil {
+		return err
+	}
+	var resp Stmt2PrepareResp
+	err = client.JsonI.Unmarshal(respBytes, &resp)
+	err = client.HandleResponseError(err, resp.Code, resp.Message)
+	if err != nil {
+		return err
+	}
+	isInsert := resp.IsInsert
+	s.isInsert = &isInsert
+	if isInsert {
+		s.fields = resp.Fields
+	} else {
+		s.fields = nil
+	}
+	return nil
+}
+
+// IsInsert reports whether the prepared sql is an insert statement.
+func (s *Stmt2) IsInsert() (bool, error) {
+	if s.isInsert == nil {
+		return false, errors.New("stmt2 is not prepared")
+	}
+	return *s.isInsert, nil
+}
+
+// Fields returns the tbname, tag and column fields of the prepared insert statement.
+func (s *Stmt2) Fields() []*stmtCommon.Stmt2AllField {
+	return s.fields
+}
+
+// Bind binds the parameters to the stmt2, the params type must equal to the DB type,
+// see af.Stmt2.Bind for the type mapping.
+func (s *Stmt2) Bind(params []*stmtCommon.TaosStmt2BindData) error {
+	if s.isInsert == nil {
+		return errors.New("stmt2 is not prepared")
+	}
+	bs, err := stmtCommon.MarshalStmt2Binary(params, *s.isInsert, s.fields)
+	if err != nil {
+		return err
+	}
+	reqID := s.connector.generateReqID()
+	reqData := make([]byte, 30)
+	binary.LittleEndian.PutUint64(reqData, reqID)
+	binary.LittleEndian.PutUint64(reqData[8:], s.id)
+	binary.LittleEndian.PutUint64(reqData[16:], Stmt2BindMessage)
+	binary.LittleEndian.PutUint16(reqData[24:], Stmt2BindProtocolVersion1)
+	// col index, -1 means bind all columns
+	binary.LittleEndian.PutUint32(reqData[26:], 0xffffffff)
+	envelope := client.GlobalEnvelopePool.Get()
+	defer client.GlobalEnvelopePool.Put(envelope)
+	envelope.Msg.Grow(len(reqData) + len(bs))
+	envelope.Msg.Write(reqData)
+	envelope.Msg.Write(bs)
+	respBytes, err := s.connector.sendBinary(reqID, envelope)
+	if err != nil {
+		return err
+	}
+	var resp Stmt2BindResp
+	err = client.JsonI.Unmarshal(respBytes, &resp)
+	return client.HandleResponseError(err, resp.Code, resp.Message)
+}
+
+func (s *Stmt2) Exec() error {
+	if s.isInsert == nil {
+		return errors.New("stmt2 is not prepared")
+	}
+	reqID := s.connector.generateReqID()
+	req := &Stmt2ExecReq{
+		ReqID:  reqID,
+		StmtID: s.id,
+	}
+	args, err := client.JsonI.Marshal(req)
+	if err != nil {
+		return err
+	}
+	action := &client.WSAction{
+		Action: STMT2Exec,
+		Args:   args,
+	}
+	envelope := client.GlobalEnvelopePool.Get()
+	defer client.GlobalEnvelopePool.Put(envelope)
+	err = client.JsonI.NewEncoder(envelope.Msg).Encode(action)
+	if err != nil {
+		return err
+	}
+	respBytes, err := s.connector.sendText(reqID, envelope)
+	if err != nil {
+		return err
+	}
+	var resp Stmt2ExecResp
+	err = client.JsonI.Unmarshal(respBytes, &resp)
+	err = client.HandleResponseError(err, resp.Code, resp.Message)
+	if err != nil {
+		return err
+	}
+	s.lastAffected = resp.Affected
+	return nil
+}
+
+func (s *Stmt2) GetAffectedRows() int {
+	return s.lastAffected
+}
+
+func (s *Stmt2) UseResult() (*Rows, error) {
+	reqID := s.connector.generateReqID()
+	req := &Stmt2UseResultReq{
+		ReqID:  reqID,
+		StmtID: s.id,
+	}
+	args, err := client.JsonI.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	action := &client.WSAction{
+		Action: STMT2Result,
+		Args:   args,
+	}
+	envelope := client.GlobalEnvelopePool.Get()
+	defer client.GlobalEnvelopePool.Put(envelope)
+	err = client.JsonI.NewEncoder(envelope.Msg).Encode(action)
+	if err != nil {
+		return nil, err
+	}
+	respBytes, err := s.connector.sendText(reqID, envelope)
+	if err != nil {
+		return nil, err
+	}
+	var resp Stmt2UseResultResp
+	err = client.JsonI.Unmarshal(respBytes, &resp)
+	err = client.HandleResponseError(err, resp.Code, resp.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{
+		buf:              &bytes.Buffer{},
+		conn:             s.connector,
+		client:           s.connector.client,
+		resultID:         resp.ResultID,
+		fieldsCount:      resp.FieldsCount,
+		fieldsNames:      resp.FieldsNames,
+		fieldsTypes:      resp.FieldsTypes,
+		fieldsLengths:    resp.FieldsLengths,
+		precision:        resp.Precision,
+		fieldsPrecisions: resp.FieldsPrecisions,
+		fieldsScales:     resp.FieldsScales,
+	}, nil
+}
+
+func (s *Stmt2) Close() error {
+	reqID := s.connector.generateReqID()
+	req := &Stmt2CloseReq{
+		ReqID:  reqID,
+		StmtID: s.id,
+	}
+	args, err := client.JsonI.Marshal(req)
+	if err != nil {
+		return err
+	}
+	action := &client.WSAction{
+		Action: STMT2Close,
+		Args:   args,
+	}
+	envelope := client.GlobalEnvelopePool.Get()
+	defer client.GlobalEnvelopePool.Put(envelope)
+	err = client.JsonI.NewEncoder(envelope.Msg).Encode(action)
+	if err != nil {
+		return err
+	}
+	respBytes, err := s.connector.sendText(reqID, envelope)
+	if err != nil {
+		return err
+	}
+	var resp Stmt2CloseResp
+	err = client.JsonI.Unmarshal(respBytes, &resp)
+	return client.HandleResponseError(err, resp.Code, resp.Message)
+}
@@ -0,0 +1,183 @@
+package stmt
+
+import (
+	"database/sql/driver"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	stmtCommon "github.com/taosdata/driver-go/v3/common/stmt"
+)
+
+func newStmt2TestConnector(t *testing.T, db string) *Connector {
+	config := NewConfig("ws://127.0.0.1:6041", 0)
+	err := config.SetConnectUser("root")
+	assert.NoError(t, err)
+	err = config.SetConnectPass("taosdata")
+	assert.NoError(t, err)
+	err = config.SetConnectDB(db)
+	assert.NoError(t, err)
+	connector, err := NewConnector(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return connector
+}
+
+func TestStmt2Insert(t *testing.T) {
+	db := "test_ws_stmt2"
+	err := prepareEnv(db)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = cleanEnv(db)
+		assert.NoError(t, err)
+	}()
+	connector := newStmt2TestConnector(t, db)
+	defer func() {
+		err = connector.Close()
+		assert.NoError(t, err)
+	}()
+	stmt2, err := connector.InitStmt2(true, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = stmt2.Close()
+		assert.NoError(t, err)
+	}()
+	err = stmt2.Prepare("insert into ? using all_json tags(?) values(?,?,?,?,?,?,?,?,?,?,?,?,?,?)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	isInsert, err := stmt2.IsInsert()
+	assert.NoError(t, err)
+	assert.True(t, isInsert)
+	assert.Equal(t, 16, len(stmt2.Fields()))
+	now := time.Now().Round(time.Millisecond)
+	row := func(ts time.Time) [][]driver.Value {
+		return [][]driver.Value{
+			{ts},
+			{true},
+			{int8(1)},
+			{int16(1)},
+			{int32(1)},
+			{int64(1)},
+			{uint8(1)},
+			{uint16(1)},
+			{uint32(1)},
+			{uint64(1)},
+			{float32(1)},
+			{float64(1)},
+			{[]byte("test_binary")},
+			{"test_nchar"},
+		}
+	}
+	params := []*stmtCommon.TaosStmt2BindData{
+		{
+			TableName: "tb1",
+			Tags:      []driver.Value{[]byte(`{"tb":1}`)},
+			Cols:      row(now),
+		},
+		{
+			TableName: "tb2",
+			Tags:      []driver.Value{[]byte(`{"tb":2}`)},
+			Cols:      row(now.Add(time.Second)),
+		},
+	}
+	err = stmt2.Bind(params)
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = stmt2.Exec()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, stmt2.GetAffectedRows())
+	result, err := query("select * from " + db + ".all_json order by ts")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 0, result.Code, result)
+	assert.Equal(t, 2, len(result.Data))
+	assert.Equal(t, now.UnixNano()/1e6, result.Data[0][0].(time.Time).UnixNano()/1e6)
+	assert.Equal(t, "test_nchar", result.Data[0][13])
+	assert.Equal(t, []byte(`{"tb":1}`), result.Data[0][14])
+	assert.Equal(t, []byte(`{"tb":2}`), result.Data[1][14])
+}
+
+func TestStmt2Query(t *testing.T) {
+	db := "test_ws_stmt2_query"
+	err := prepareEnv(db)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = cleanEnv(db)
+		assert.NoError(t, err)
+	}()
+	now := time.Now().Round(time.Millisecond)
+	err = doRequest("insert into " + db + ".tb1 using " + db + ".all_json tags('{\"tb\":1}') (ts,c4) values(" + strconv.FormatInt(now.UnixNano()/1e6, 10) + ",1)")
+	if !assert.NoError(t, err) {
+		return
+	}
+	connector := newStmt2TestConnector(t, db)
+	defer func() {
+		err = connector.Close()
+		assert.NoError(t, err)
+	}()
+	stmt2, err := connector.InitStmt2(false, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = stmt2.Close()
+		assert.NoError(t, err)
+	}()
+	err = stmt2.Prepare("select ts,c4 from all_json where c4 = ?")
+	if !assert.NoError(t, err) {
+		return
+	}
+	isInsert, err := stmt2.IsInsert()
+	assert.NoError(t, err)
+	assert.False(t, isInsert)
+	err = stmt2.Bind([]*stmtCommon.TaosStmt2BindData{{Cols: [][]driver.Value{{int32(1)}}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = stmt2.Exec()
+	if !assert.NoError(t, err) {
+		return
+	}
+	rows, err := stmt2.UseResult()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		err = rows.Close()
+		assert.NoError(t, err)
+	}()
+	assert.Equal(t, []string{"ts", "c4"}, rows.Columns())
+	dest := make([]driver.Value, 2)
+	err = rows.Next(dest)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, now.UnixNano()/1e6, dest[0].(time.Time).UnixNano()/1e6)
+	assert.Equal(t, int32(1), dest[1])
+	err = rows.Next(dest)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestStmt2NotPrepared(t *testing.T) {
+	stmt2 := &Stmt2{}
+	_, err := stmt2.IsInsert()
+	assert.Error(t, err)
+	err = stmt2.Bind(nil)
+	assert.Error(t, err)
+	err = stmt2.Exec()
+	assert.Error(t, err)
+}
@@ -1208,3 +1208,11 @@ func TestSTMTReconnect(t *testing.T) {
 	err = stmtNew.Close()
 	assert.NoError(t, err)
 }
+
+func TestNewConnectorTLSConfig(t *testing.T) {
+	config := NewConfig("wss://127.0.0.1:6041", 0)
+	config.SetTLSConfig("unregistered")
+	connector, err := NewConnector(config)
+	assert.Nil(t, connector)
+	assert.Error(t, err)
+}
@@ -2,7 +2,11 @@ package tmq
 
 import (
 	"errors"
+	"strconv"
 	"time"
+
+	"github.com/taosdata/driver-go/v3/common/endpoint"
+	"github.com/taosdata/driver-go/v3/common/tmq"
 )
 
 type config struct {
@@ -26,6 +30,9 @@ type config struct {
 	SessionTimeoutMS     string
 	MaxPollIntervalMS    string
 	OtherOptions         map[string]string
+	EndpointPolicy       string
+	TLSConfig            string
+	RebalanceEvents      bool
 }
 
 func newConfig(url string, chanLength uint) *config {
@@ -111,3 +118,27 @@ func (c *config) setSessionTimeoutMS(sessionTimeoutMS string) {
 func (c *config) setMaxPollIntervalMS(maxPollIntervalMS string) {
 	c.MaxPollIntervalMS = maxPollIntervalMS
 }
+
+func (c *config) setEndpointPolicy(policy string) error {
+	if policy != "" && !endpoint.IsValidPolicy(policy) {
+		return errors.New("ws.endpointPolicy must be one of roundRobin, random and firstAvailable")
+	}
+	c.EndpointPolicy = policy
+	return nil
+}
+
+func (c *config) setTLSConfig(name string) {
+	c.TLSConfig = name
+}
+
+func (c *config) setRebalanceEvents(enable string) error {
+	if enable == "" {
+		return nil
+	}
+	rebalanceEvents, err := strconv.ParseBool(enable)
+	if err != nil {
+		return errors.New(tmq.RebalanceEventsEnableKey + " must be true or false")
+	}
+	c.RebalanceEvents = rebalanceEvents
+	return nil
+}
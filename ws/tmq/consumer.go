@@ -17,6 +17,7 @@ import (
 	"github.com/gorilla/websocket"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/taosdata/driver-go/v3/common"
+	"github.com/taosdata/driver-go/v3/common/endpoint"
 	"github.com/taosdata/driver-go/v3/common/parser"
 	"github.com/taosdata/driver-go/v3/common/tmq"
 	taosErrors "github.com/taosdata/driver-go/v3/errors"
@@ -34,7 +35,7 @@ type Consumer struct {
 	autoCommit          bool
 	autoCommitInterval  time.Duration
 	nextAutoCommitTime  time.Time
-	url                 string
+	balancer            *endpoint.Balancer
 	user                string
 	password            string
 	groupID             string
@@ -54,6 +55,7 @@ type Consumer struct {
 	chanLength          uint
 	writeWait           time.Duration
 	dialer              *websocket.Dialer
+	rebalancer          *tmq.Rebalancer
 }
 
 type IndexedChan struct {
@@ -91,12 +93,20 @@ func NewConsumer(conf *tmq.ConfigMap) (*Consumer, error) {
 
 	dialer := common.DefaultDialer
 	dialer.EnableCompression = config.EnableCompression
+	dialer.TLSClientConfig, err = common.GetTLSConfig(config.TLSConfig)
+	if err != nil {
+		return nil, errors.New("ws.tls: " + err.Error())
+	}
 	u, err := url.Parse(config.Url)
 	if err != nil {
 		return nil, err
 	}
 	u.Path = "/rest/tmq"
-	ws, _, err := dialer.Dial(u.String(), nil)
+	balancer, err := endpoint.NewBalancer(endpoint.SplitURL(u), config.EndpointPolicy)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := endpoint.Dial(&dialer, balancer)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +118,7 @@ func NewConsumer(conf *tmq.ConfigMap) (*Consumer, error) {
 		requestID:           0,
 		sendChanList:        list.New(),
 		messageTimeout:      config.MessageTimeout,
-		url:                 u.String(),
+		balancer:            balancer,
 		user:                config.User,
 		password:            config.Password,
 		groupID:             config.GroupID,
@@ -127,6 +137,7 @@ func NewConsumer(conf *tmq.ConfigMap) (*Consumer, error) {
 		writeWait:           config.WriteWait,
 		otherOptions:        config.OtherOptions,
 		dialer:              &dialer,
+		rebalancer:          tmq.NewRebalancer(nil, config.RebalanceEvents),
 	}
 	consumer.initClient(consumer.client)
 	return consumer, nil
@@ -147,7 +158,7 @@ func (c *Consumer) reconnect() error {
 	reconnected := false
 	for i := 0; i < c.reconnectRetryCount; i++ {
 		time.Sleep(time.Duration(c.reconnectIntervalMs) * time.Millisecond)
-		conn, _, err := c.dialer.Dial(c.url, nil)
+		conn, err := endpoint.Dial(c.dialer, c.balancer)
 		if err != nil {
 			continue
 		}
@@ -192,6 +203,9 @@ var excludeConfig = map[string]struct{}{
 	"ws.autoReconnect":             {},
 	"ws.reconnectIntervalMs":       {},
 	"ws.reconnectRetryCount":       {},
+	"ws.endpointPolicy":            {},
+	"ws.tls":                       {},
+	tmq.RebalanceEventsEnableKey:   {},
 	"session.timeout.ms":           {},
 	"max.poll.interval.ms":         {},
 }
@@ -277,6 +291,18 @@ func configMapToConfig(m tmq.ConfigMap) (*config, error) {
 	if err != nil {
 		return nil, err
 	}
+	endpointPolicy, err := m.Get("ws.endpointPolicy", "")
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := m.Get("ws.tls", "")
+	if err != nil {
+		return nil, err
+	}
+	rebalanceEvents, err := m.Get(tmq.RebalanceEventsEnableKey, "")
+	if err != nil {
+		return nil, err
+	}
 	config := newConfig(url.(string), chanLen.(uint))
 	err = config.setMessageTimeout(messageTimeout.(time.Duration))
 	if err != nil {
@@ -301,6 +327,15 @@ func configMapToConfig(m tmq.ConfigMap) (*config, error) {
 	config.setReconnectRetryCount(reconnectRetryCount.(int))
 	config.setSessionTimeoutMS(sessionTimeoutMS.(string))
 	config.setMaxPollIntervalMS(maxPollIntervalMS.(string))
+	err = config.setEndpointPolicy(endpointPolicy.(string))
+	if err != nil {
+		return nil, err
+	}
+	config.setTLSConfig(tlsConfig.(string))
+	err = config.setRebalanceEvents(rebalanceEvents.(string))
+	if err != nil {
+		return nil, err
+	}
 	for k, v := range m {
 		if _, ok := excludeConfig[k]; ok {
 			continue
@@ -358,13 +393,18 @@ func (c *Consumer) generateReqID() uint64 {
 	return atomic.AddUint64(&c.requestID, 1)
 }
 
-// Close consumer. This function can be called multiple times
+// Close the consumer, the assigned partitions are revoked by the rebalance callback before closing.
+// This function can be called multiple times
 func (c *Consumer) Close() error {
+	var err error
+	if c.err == nil {
+		err = c.rebalancer.RevokeAll(c)
+	}
 	c.closeOnce.Do(func() {
 		close(c.closeChan)
 		c.client.Close()
 	})
-	return nil
+	return err
 }
 
 func (c *Consumer) addMessageOutChan(outChan *IndexedChan) *list.Element {
@@ -448,14 +488,32 @@ func (c *Consumer) sendText(reqID uint64, envelope *client.Envelope) ([]byte, er
 	}
 }
 
-type RebalanceCb func(*Consumer, tmq.Event) error
+// RebalanceCb is the rebalance callback shared with af/tmq
+type RebalanceCb = tmq.RebalanceCb
+
+var _ tmq.Consumer = (*Consumer)(nil)
+
+func init() {
+	tmq.RegisterConsumer(tmq.ConnectTypeWebSocket, func(conf *tmq.ConfigMap) (tmq.Consumer, error) {
+		consumer, err := NewConsumer(conf)
+		if err != nil {
+			return nil, err
+		}
+		return consumer, nil
+	})
+}
 
 func (c *Consumer) Subscribe(topic string, rebalanceCb RebalanceCb) error {
 	return c.SubscribeTopics([]string{topic}, rebalanceCb)
 }
 
 func (c *Consumer) SubscribeTopics(topics []string, rebalanceCb RebalanceCb) error {
-	return c.doSubscribe(topics, c.autoReconnect)
+	err := c.doSubscribe(topics, c.autoReconnect)
+	if err != nil {
+		return err
+	}
+	c.rebalancer.Subscribe(rebalanceCb)
+	return nil
 }
 
 func (c *Consumer) doSubscribe(topics []string, reconnect bool) error {
@@ -522,11 +580,15 @@ func (c *Consumer) doSubscribe(topics []string, reconnect bool) error {
 	return nil
 }
 
-// Poll messages
+// Poll messages. The assignment is checked before polling, when it changes AssignedPartitions and RevokedPartitions
+// are passed to the rebalance callback, or returned if there is no callback and rebalance.events.enable is true.
 func (c *Consumer) Poll(timeoutMs int) tmq.Event {
 	if c.err != nil {
 		return tmq.NewTMQErrorWithErr(c.err)
 	}
+	if event := c.rebalancer.Poll(c); event != nil {
+		return event
+	}
 	if c.autoCommit {
 		if c.nextAutoCommitTime.IsZero() {
 			c.nextAutoCommitTime = time.Now().Add(c.autoCommitInterval)
@@ -681,12 +743,7 @@ func (c *Consumer) fetchJsonMeta(messageID uint64) (*tmq.Meta, error) {
 	if err != nil {
 		return nil, err
 	}
-	var meta tmq.Meta
-	err = client.JsonI.Unmarshal(resp.Data, &meta)
-	if err != nil {
-		return nil, err
-	}
-	return &meta, nil
+	return tmq.ParseMeta(resp.Data)
 }
 
 func (c *Consumer) fetch(messageID uint64) ([]*tmq.Data, error) {
@@ -719,14 +776,11 @@ func (c *Consumer) fetch(messageID uint64) ([]*tmq.Data, error) {
 	}
 	tmqData := make([]*tmq.Data, len(blockInfo))
 	for i := 0; i < len(blockInfo); i++ {
-		data, err := parser.ReadBlockSimple(blockInfo[i].RawBlock, blockInfo[i].Precision)
+		data, err := parser.ReadTMQData(blockInfo[i])
 		if err != nil {
 			return nil, err
 		}
-		tmqData[i] = &tmq.Data{
-			TableName: blockInfo[i].TableName,
-			Data:      data,
-		}
+		tmqData[i] = data
 	}
 	return tmqData, nil
 }
@@ -775,10 +829,21 @@ func (c *Consumer) doCommit() error {
 	return client.HandleResponseError(err, resp.Code, resp.Message)
 }
 
+// Unsubscribe the topics, the assigned partitions are revoked before unsubscribing.
+// The consumer is unsubscribed even if the rebalance callback fails, the error of the callback is returned then.
 func (c *Consumer) Unsubscribe() error {
 	if c.err != nil {
 		return c.err
 	}
+	revokeErr := c.rebalancer.RevokeAll(c)
+	if err := c.doUnsubscribe(); err != nil {
+		return err
+	}
+	c.rebalancer.Unsubscribed()
+	return revokeErr
+}
+
+func (c *Consumer) doUnsubscribe() error {
 	reqID := c.generateReqID()
 	req := &UnsubscribeReq{
 		ReqID: reqID,
@@ -843,6 +843,16 @@ func Test_configMapToConfigWrong(t *testing.T) {
 			},
 			wantErr: "config min.poll.rows value must be string",
 		},
+		{
+			name: "ws.endpointPolicy",
+			args: args{
+				m: tmq.ConfigMap{
+					"ws.url":            "ws://127.0.0.1:6041,127.0.0.1:6042",
+					"ws.endpointPolicy": "wrong",
+				},
+			},
+			wantErr: "ws.endpointPolicy must be one of roundRobin, random and firstAvailable",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -853,6 +863,18 @@ func Test_configMapToConfigWrong(t *testing.T) {
 	}
 }
 
+func TestNewConsumerTLSConfig(t *testing.T) {
+	consumer, err := NewConsumer(&tmq.ConfigMap{
+		"ws.url":   "wss://127.0.0.1:6041",
+		"ws.tls":   "unregistered",
+		"group.id": "test",
+	})
+	assert.Nil(t, consumer)
+	if assert.Error(t, err) {
+		assert.Equal(t, "ws.tls: tls config unregistered is not registered", err.Error())
+	}
+}
+
 func prepareMetaEnv() error {
 	var err error
 	steps := []string{